@@ -0,0 +1,150 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"cmp"
+	"encoding/binary"
+	"slices"
+	"unicode/utf8"
+)
+
+// A read-only, minimized version of a Trie, made by Trie.Minimize. Nodes
+// that have the same strings below them are merged into one, no matter
+// where they are, so shared suffixes ("-ing", "-tion", ...) are only
+// stored once rather than once per word. For big dictionaries, that's
+// usually far fewer nodes than the Trie took.
+//
+// A DAWG can't be changed once made, so it's safe to use from multiple
+// goroutines at once.
+type DAWG struct {
+	root      *dawgNode
+	size      int
+	nodes     int
+	fold      bool
+	normalize bool
+}
+
+// Since nodes are shared, a dawgNode doesn't know its own rune; that's
+// on the edge leading to it.
+type dawgNode struct {
+	// Sorted by rune.
+	edges []dawgEdge
+	isEnd bool
+}
+
+type dawgEdge struct {
+	r    rune
+	node *dawgNode
+}
+
+// Builds a DAWG holding the same strings as the trie, with the same
+// options (folding, etc.). The trie isn't changed, and changing it later
+// doesn't change the DAWG.
+//
+// Never returns nil.
+func (t *Trie) Minimize() *DAWG {
+	d := &DAWG{
+		size:      t.size,
+		fold:      t.fold,
+		normalize: t.normalize,
+	}
+
+	// Every distinct node made so far, keyed by what makes it distinct:
+	// whether it's an end, and the runes and (already merged) nodes
+	// below it. Children are merged before their parents, so two nodes
+	// with the same strings below them always get the same key.
+	registry := make(map[string]*dawgNode)
+	ids := make(map[*dawgNode]uint64)
+	var key []byte
+	var minimize func(node *trieNode) *dawgNode
+	minimize = func(node *trieNode) *dawgNode {
+		out := &dawgNode{
+			edges: make([]dawgEdge, 0, node.numChildren()),
+			isEnd: node.isEnd,
+		}
+		for r, child := range node.sortedChildren() {
+			out.edges = append(out.edges, dawgEdge{r, minimize(child)})
+		}
+
+		key = key[:0]
+		if out.isEnd {
+			key = append(key, 1)
+		} else {
+			key = append(key, 0)
+		}
+		for _, e := range out.edges {
+			key = binary.AppendVarint(key, int64(e.r))
+			key = binary.AppendUvarint(key, ids[e.node])
+		}
+		if existing, ok := registry[string(key)]; ok {
+			return existing
+		}
+		registry[string(key)] = out
+		ids[out] = uint64(len(ids))
+		return out
+	}
+	d.root = minimize(&t.root)
+	// The root's in there too. It's never merged with anything, since
+	// it's the only node with its longest string below it.
+	d.nodes = len(registry) - 1
+	return d
+}
+
+// Returns the node for s, or nil if there isn't one (or utf8 decode
+// error).
+func (d *DAWG) searchNode(s string) *dawgNode {
+	current := d.root
+	for len(s) != 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			return nil
+		}
+		i, found := slices.BinarySearchFunc(current.edges, r, func(e dawgEdge, r rune) int {
+			return cmp.Compare(e.r, r)
+		})
+		if !found {
+			return nil
+		}
+		current = current.edges[i].node
+		s = s[size:]
+	}
+	return current
+}
+
+// Searches for the given string, same as Trie.Has.
+func (d *DAWG) Has(s string) bool {
+	node := d.searchNode(canonicalize(s, d.fold, d.normalize))
+	return node != nil && node.isEnd
+}
+
+// Searches for the given prefix, same as Trie.HasPrefix.
+func (d *DAWG) HasPrefix(s string) bool {
+	node := d.searchNode(canonicalize(s, d.fold, d.normalize))
+	return node != nil && (node.isEnd || len(node.edges) != 0)
+}
+
+// Returns the number of strings in the DAWG.
+func (d *DAWG) Len() int {
+	return d.size
+}
+
+// Returns the number of distinct nodes in the DAWG, not counting the
+// root; comparable to Trie.NodeCount.
+func (d *DAWG) NodeCount() int {
+	return d.nodes
+}
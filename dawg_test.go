@@ -0,0 +1,102 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDAWGHas(t *testing.T) {
+	words := []string{"tap", "taps", "top", "tops", "jump", "jumping", "walking", "walk", "talking", "é", ""}
+	trie := newTrieOf(words...)
+	d := trie.Minimize()
+
+	if d.Len() != trie.Len() {
+		t.Fatal("Expected Len", trie.Len(), "got", d.Len())
+	}
+	queries := append(words, "ta", "tal", "taps2", "jumpin", "walkin", "alking", "king", "x", "\xff", "wal\xffk")
+	for _, q := range queries {
+		if d.Has(q) != trie.Has(q) {
+			t.Fatalf("Has(%q): expected %v", q, trie.Has(q))
+		}
+		for i := range len(q) + 1 {
+			if d.HasPrefix(q[:i]) != trie.HasPrefix(q[:i]) {
+				t.Fatalf("HasPrefix(%q): expected %v", q[:i], trie.HasPrefix(q[:i]))
+			}
+		}
+	}
+
+	// The DAWG is a snapshot.
+	trie.Put("new")
+	if d.Has("new") {
+		t.Fatal("Expected the DAWG not to see changes to the trie")
+	}
+}
+
+func TestDAWGNodeCount(t *testing.T) {
+	// The nodes after "ca" and "do" both end a string and lead to "s",
+	// which ends one too, so those two merge, and so do the two "s"s:
+	// c, a, d, o, t|g and s|s is 6 nodes instead of 8.
+	trie := newTrieOf("cat", "cats", "dog", "dogs")
+	if d := trie.Minimize(); d.NodeCount() != 6 || trie.NodeCount() != 8 {
+		t.Fatal("Expected 6 nodes rather than 8; got", d.NodeCount(), trie.NodeCount())
+	}
+
+	if NewTrie().Minimize().NodeCount() != 0 {
+		t.Fatal("Expected an empty DAWG to have no nodes")
+	}
+	if d := newTrieOf("").Minimize(); d.NodeCount() != 0 || !d.Has("") {
+		t.Fatal("Expected a DAWG of just the empty string to have no nodes")
+	}
+}
+
+func TestDAWGOptions(t *testing.T) {
+	fold := NewTrieFold()
+	fold.Put("Hello")
+	if d := fold.Minimize(); !d.Has("HELLO") || !d.HasPrefix("hE") {
+		t.Fatal("Expected a DAWG from a folding trie to fold too")
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+// Builds a dictionary with lots of shared suffixes, the way real words
+// have them, and reports how many nodes the trie and the DAWG take.
+func BenchmarkTrieMinimize(b *testing.B) {
+	suffixes := []string{"", "s", "ed", "ing", "er", "ers", "able", "ness", "ly"}
+	rand.Seed(0) // Arbitrary seed
+
+	trie := NewTrie()
+	buf := make([]rune, 6)
+	for i := 0; i < 20000; i++ {
+		for j := range buf {
+			buf[j] = rune('a' + rand.Intn(26))
+		}
+		for _, suffix := range suffixes {
+			trie.Put(string(buf) + suffix)
+		}
+	}
+
+	b.ResetTimer()
+	var d *DAWG
+	for i := 0; i < b.N; i++ {
+		d = trie.Minimize()
+	}
+	b.ReportMetric(float64(trie.NodeCount()), "trie-nodes")
+	b.ReportMetric(float64(d.NodeCount()), "dawg-nodes")
+}
@@ -0,0 +1,194 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// A read-only copy of a Trie, made by Trie.Freeze, laid out for fast
+// lookups. Every node lives in one flat slice, with each node's children
+// next to each other. The runs of children are laid out depth-first, so
+// the long single-child tails of strings are contiguous too, rather than
+// scattered around the heap. Short runs of children are scanned. Wide nodes whose
+// runes are close together get a table indexed by rune, so their
+// children are found without hashing or searching. Any other node is
+// binary searched.
+//
+// Unlike a DAWG, nothing is merged, so a FrozenTrie takes about as many
+// nodes as the Trie it came from; they're just smaller and closer
+// together. It can't be changed once made, so it's safe to use from
+// multiple goroutines at once.
+type FrozenTrie struct {
+	// nodes[0] is the root. labels[i] is the rune on the edge into
+	// nodes[i]; labels[0] is unused.
+	nodes  []frozenNode
+	labels []rune
+	// The tables for wide nodes (see frozenNode.index), one after
+	// another. index[0] is unused, so an offset of 0 means no table.
+	index     []uint32
+	size      int
+	fold      bool
+	normalize bool
+}
+
+type frozenNode struct {
+	// The node's children are nodes[first:first+n], sorted by rune.
+	first, n uint32
+	// If not 0, the node's table starts at index[index]. It has an entry
+	// for every rune from its first child's to its last child's: 0 if
+	// there's no child for that rune, or else the child's position among
+	// the node's children plus 1.
+	index uint32
+	isEnd bool
+}
+
+const (
+	// Children lists at most this long are scanned rather than looked
+	// up.
+	frozenLinearSearch = 8
+	// A node only gets a table if its children's runes span at most this
+	// many times as many runes as it has children, so sparse nodes don't
+	// waste memory.
+	frozenMaxSpread = 4
+)
+
+// Builds a FrozenTrie holding the same strings as the trie, with the same
+// options (folding, etc.). The trie isn't changed, and changing it later
+// doesn't change the FrozenTrie.
+//
+// Never returns nil.
+func (t *Trie) Freeze() *FrozenTrie {
+	n := t.root.countNodes()
+	f := &FrozenTrie{
+		nodes:     make([]frozenNode, 0, n),
+		labels:    make([]rune, 0, n),
+		index:     []uint32{0},
+		size:      t.size,
+		fold:      t.fold,
+		normalize: t.normalize,
+	}
+
+	f.nodes = append(f.nodes, frozenNode{})
+	f.labels = append(f.labels, 0)
+	f.place(0, &t.root)
+	return f
+}
+
+// Fills in nodes[i] from node, and places everything below it.
+func (f *FrozenTrie) place(i int, node *trieNode) {
+	first := len(f.nodes)
+	children := make([]*trieNode, 0, node.numChildren())
+	for r, child := range node.sortedChildren() {
+		children = append(children, child)
+		f.nodes = append(f.nodes, frozenNode{})
+		f.labels = append(f.labels, r)
+	}
+	f.nodes[i] = frozenNode{
+		first: uint32(first),
+		n:     uint32(len(children)),
+		isEnd: node.isEnd,
+	}
+	f.buildIndex(i)
+	for j, child := range children {
+		f.place(first+j, child)
+	}
+}
+
+// Gives nodes[i] a table, if it's wide and dense enough to be worth one.
+// Its children's labels must already be in place.
+func (f *FrozenTrie) buildIndex(i int) {
+	node := &f.nodes[i]
+	if node.n <= frozenLinearSearch {
+		return
+	}
+	labels := f.labels[node.first : node.first+node.n]
+	lo := labels[0]
+	spread := int(labels[len(labels)-1]-lo) + 1
+	if spread > frozenMaxSpread*int(node.n) {
+		return
+	}
+	node.index = uint32(len(f.index))
+	f.index = append(f.index, make([]uint32, spread)...)
+	for j, r := range labels {
+		f.index[int(node.index)+int(r-lo)] = uint32(j) + 1
+	}
+}
+
+// Returns the index of the node for s, or -1 if there isn't one (or utf8
+// decode error).
+func (f *FrozenTrie) searchNode(s string) int {
+	current := 0
+	for len(s) != 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			return -1
+		}
+		node := f.nodes[current]
+		labels := f.labels[node.first : node.first+node.n]
+		i, found := 0, false
+		if node.index != 0 {
+			off := uint32(r - labels[0])
+			if off <= uint32(labels[len(labels)-1]-labels[0]) {
+				if j := f.index[node.index+off]; j != 0 {
+					i, found = int(j-1), true
+				}
+			}
+		} else if len(labels) <= frozenLinearSearch {
+			for ; i < len(labels); i++ {
+				if labels[i] == r {
+					found = true
+					break
+				}
+			}
+		} else {
+			i, found = slices.BinarySearch(labels, r)
+		}
+		if !found {
+			return -1
+		}
+		current = int(node.first) + i
+		s = s[size:]
+	}
+	return current
+}
+
+// Converts s into the form it's stored in, same as Trie.canonical.
+func (f *FrozenTrie) canonical(s string) string {
+	if !(f.fold || f.normalize) {
+		return s
+	}
+	return canonicalize(s, f.fold, f.normalize)
+}
+
+// Searches for the given string, same as Trie.Has.
+func (f *FrozenTrie) Has(s string) bool {
+	i := f.searchNode(f.canonical(s))
+	return i >= 0 && f.nodes[i].isEnd
+}
+
+// Searches for the given prefix, same as Trie.HasPrefix.
+func (f *FrozenTrie) HasPrefix(s string) bool {
+	i := f.searchNode(f.canonical(s))
+	return i >= 0 && (f.nodes[i].isEnd || f.nodes[i].n != 0)
+}
+
+// Returns the number of strings in the FrozenTrie.
+func (f *FrozenTrie) Len() int {
+	return f.size
+}
@@ -0,0 +1,68 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFrozenTrieHas(t *testing.T) {
+	words := []string{"tap", "taps", "top", "jump", "jumping", "é", "日本", ""}
+	// Enough children under "x" to get a table, and under "y", spread
+	// too far apart for one, to need a binary search.
+	for i := 0; i < 40; i++ {
+		words = append(words, fmt.Sprintf("x%c", 'A'+i), fmt.Sprintf("y%c", 'A'+100*i))
+	}
+	trie := newTrieOf(words...)
+	f := trie.Freeze()
+
+	if f.Len() != trie.Len() {
+		t.Fatal("Expected Len", trie.Len(), "got", f.Len())
+	}
+	queries := append(words, "ta", "taps2", "jumpin", "日", "x", "x~", "x@", "xAA", "y", "yB", "y\u0100", "\xff", "ju\xffmp")
+	for _, q := range queries {
+		if f.Has(q) != trie.Has(q) {
+			t.Fatalf("Has(%q): expected %v", q, trie.Has(q))
+		}
+		for i := range len(q) + 1 {
+			if f.HasPrefix(q[:i]) != trie.HasPrefix(q[:i]) {
+				t.Fatalf("HasPrefix(%q): expected %v", q[:i], trie.HasPrefix(q[:i]))
+			}
+		}
+	}
+
+	// The FrozenTrie is a snapshot.
+	trie.Put("new")
+	if f.Has("new") {
+		t.Fatal("Expected the FrozenTrie not to see changes to the trie")
+	}
+}
+
+func TestFrozenTrieKeepsOptions(t *testing.T) {
+	trie := NewTrieFold()
+	trie.Put("Hello")
+	f := trie.Freeze()
+	if !f.Has("HELLO") || !f.HasPrefix("hEl") {
+		t.Fatal("Expected a frozen folding trie to fold lookups")
+	}
+
+	empty := NewTrie().Freeze()
+	if empty.Has("") || empty.HasPrefix("") || empty.Len() != 0 {
+		t.Fatal("Expected a frozen empty trie to hold nothing")
+	}
+}
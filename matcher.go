@@ -0,0 +1,417 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bufio"
+	"cmp"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Finds every string in a trie inside of a larger text in a single pass,
+// using the Aho-Corasick algorithm. Made by Trie.BuildMatcher.
+//
+// A Matcher is a snapshot: changing the trie afterward doesn't change
+// what it finds. It's never modified once built, so it's safe to use from
+// multiple goroutines at once.
+type Matcher struct {
+	root *matcherNode
+	// The most runes in any pattern; how far back a match can start.
+	maxRunes int
+	// If set, patterns and text are lowercased with unicode.ToLower
+	// before being matched. See BuildMatcherFold.
+	fold bool
+	// If set, only matches that are whole words are reported. See
+	// WholeWords.
+	wholeWords bool
+}
+
+// A single place a pattern was found in a text. Start and End are byte
+// offsets, so the matched text is text[Start:End].
+type Match struct {
+	Pattern    string
+	Start, End int
+}
+
+type matcherNode struct {
+	children map[rune]*matcherNode
+	// The node for the longest proper suffix of this node's string that's
+	// also in the automaton. nil for the root.
+	fail *matcherNode
+	// The first node along the fail links that's the end of a pattern,
+	// so reporting matches doesn't have to check every one of them.
+	out *matcherNode
+	// The pattern that ends here, if any.
+	pattern string
+	isEnd   bool
+	// How many runes deep this node is.
+	depth int
+}
+
+// Builds a Matcher that finds every string currently in the trie. If the
+// trie was made by NewTrieFold, the Matcher ignores case like
+// BuildMatcherFold's does.
+//
+// The empty string never matches anything, even if it's in the trie.
+//
+// Never returns nil.
+func (t *Trie) BuildMatcher() *Matcher {
+	return t.buildMatcher(t.fold)
+}
+
+// Same as BuildMatcher, but the Matcher ignores case: both the patterns
+// and the text are lowercased with unicode.ToLower, one rune at a time,
+// before they're compared. Offsets in each Match still point into the
+// original text, even where lowercasing a rune changes how many bytes it
+// takes up. Pattern is given lowercased.
+//
+// Never returns nil.
+func (t *Trie) BuildMatcherFold() *Matcher {
+	return t.buildMatcher(true)
+}
+
+func (t *Trie) buildMatcher(fold bool) *Matcher {
+	m := &Matcher{root: &matcherNode{}, fold: fold}
+	t.Walk(func(word string) bool {
+		m.add(word)
+		return true
+	})
+	m.link()
+	return m
+}
+
+// Adds pattern to the automaton's tree. link has to be (re)run after.
+func (m *Matcher) add(pattern string) {
+	if pattern == "" {
+		return
+	}
+	if m.fold {
+		pattern = strings.Map(unicode.ToLower, pattern)
+	}
+	node := m.root
+	for _, r := range pattern {
+		next := node.children[r]
+		if next == nil {
+			if node.children == nil {
+				node.children = make(map[rune]*matcherNode)
+			}
+			next = &matcherNode{depth: node.depth + 1}
+			node.children[r] = next
+		}
+		node = next
+	}
+	node.isEnd = true
+	node.pattern = pattern
+	m.maxRunes = max(m.maxRunes, node.depth)
+}
+
+// Sets up the fail and out links, breadth first so that every node's
+// links are set before its children need them.
+func (m *Matcher) link() {
+	queue := []*matcherNode{m.root}
+	for len(queue) != 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for r, child := range node.children {
+			child.fail = m.root
+			for f := node.fail; f != nil; f = f.fail {
+				if next := f.children[r]; next != nil {
+					child.fail = next
+					break
+				}
+			}
+			if child.fail.isEnd {
+				child.out = child.fail
+			} else {
+				child.out = child.fail.out
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// Returns a Matcher that finds the same patterns as this one, but only
+// where they're whole words: the rune before a match and the rune after it
+// can't be letters or digits (per unicode.IsLetter and unicode.IsDigit).
+// The start and end of the text count as boundaries, as do bad bytes. So
+// "class" matches in "a class." but not in "classic" or "subclass".
+//
+// Everything that finds matches (FindAll, FindAllReader, Replace and
+// Censor) goes by this. The automaton is shared, not copied.
+//
+// Never returns nil.
+func (m *Matcher) WholeWords() *Matcher {
+	whole := *m
+	whole.wholeWords = true
+	return &whole
+}
+
+// The state of a single scan through a text.
+type matchScanner struct {
+	m    *Matcher
+	node *matcherNode
+	// How many runes have been fed in.
+	runes int
+	// Where each of the last maxRunes runes started, and the rune before
+	// each one (noRune if there wasn't one), indexed by rune count mod
+	// maxRunes.
+	starts  []int
+	befores []rune
+	// The last rune fed in, or noRune at the start or after a bad byte.
+	last rune
+	// For whole word matchers, the matches that end at the last rune,
+	// which can't be emitted until it's known what comes after them.
+	pending []Match
+	emit    func(Match) bool
+}
+
+// Stands in for the rune next to a match at the edge of a text, or next
+// to a bad byte. Never a word rune.
+const noRune = -1
+
+func (m *Matcher) newScanner(emit func(Match) bool) *matchScanner {
+	return &matchScanner{
+		m:       m,
+		node:    m.root,
+		starts:  make([]int, max(m.maxRunes, 1)),
+		befores: make([]rune, max(m.maxRunes, 1)),
+		last:    noRune,
+		emit:    emit,
+	}
+}
+
+// Reports whether r is part of a word, as far as WholeWords is concerned.
+func isWordRune(r rune) bool {
+	return r != noRune && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// Feeds the next rune of the text through the automaton, where the rune
+// was text[start:end]. Hands every match that ends with it to emit,
+// longest first (for whole word matchers, that happens once the rune
+// after it is fed in, or the scan finishes).
+//
+// Returns false if emit asked to stop.
+func (s *matchScanner) feed(r rune, start, end int) bool {
+	if !s.flush(r) {
+		return false
+	}
+
+	i := s.runes % len(s.starts)
+	s.starts[i], s.befores[i] = start, s.last
+	s.runes++
+	s.last = r
+	if s.m.fold {
+		r = unicode.ToLower(r)
+	}
+
+	node := s.node
+	for node != s.m.root && node.children[r] == nil {
+		node = node.fail
+	}
+	if next := node.children[r]; next != nil {
+		node = next
+	}
+	s.node = node
+
+	if !node.isEnd {
+		node = node.out
+	}
+	for ; node != nil; node = node.out {
+		first := (s.runes - node.depth) % len(s.starts)
+		match := Match{
+			Pattern: node.pattern,
+			Start:   s.starts[first],
+			End:     end,
+		}
+		if s.m.wholeWords {
+			if !isWordRune(s.befores[first]) {
+				s.pending = append(s.pending, match)
+			}
+		} else if !s.emit(match) {
+			return false
+		}
+	}
+	return true
+}
+
+// Emits pending matches, now that next is known to follow them.
+//
+// Returns false if emit asked to stop.
+func (s *matchScanner) flush(next rune) bool {
+	pending := s.pending
+	s.pending = s.pending[:0]
+	if isWordRune(next) {
+		return true
+	}
+	for _, match := range pending {
+		if !s.emit(match) {
+			return false
+		}
+	}
+	return true
+}
+
+// Starts over, as if nothing had been fed in. Used where the text isn't
+// valid utf8, since no pattern can match across a bad byte.
+//
+// Returns false if emit asked to stop.
+func (s *matchScanner) reset() bool {
+	s.node = s.m.root
+	s.last = noRune
+	return s.flush(noRune)
+}
+
+// Emits anything still pending at the end of the text.
+func (s *matchScanner) finish() {
+	s.flush(noRune)
+}
+
+// Runs all of text through the scanner.
+func (s *matchScanner) scanString(text string) {
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r == utf8.RuneError && size == 1 {
+			if !s.reset() {
+				return
+			}
+		} else if !s.feed(r, i, i+size) {
+			return
+		}
+		i += size
+	}
+	s.finish()
+}
+
+// Returns every place a pattern shows up in text, including ones that
+// overlap or sit inside of other matches. Matches are in order of where
+// they end; ones that end in the same place come longest first.
+//
+// Bytes in text that aren't valid utf8 never match anything.
+//
+// Never returns nil.
+func (m *Matcher) FindAll(text string) []Match {
+	matches := []Match{}
+	m.newScanner(func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	}).scanString(text)
+	return matches
+}
+
+// Returns the number of matches in text, without keeping track of them.
+// Counts the same matches FindAll would return, overlaps and all, so it's
+// always len(m.FindAll(text)), only cheaper.
+func (m *Matcher) CountMatches(text string) int {
+	n := 0
+	m.newScanner(func(Match) bool {
+		n++
+		return true
+	}).scanString(text)
+	return n
+}
+
+// Same as FindAll, but reads the text from r as it goes rather than
+// needing all of it in memory, and hands each match to fn instead of
+// returning them. Offsets are bytes from the start of what's read from r.
+// Runes split across reads are put back together.
+//
+// Stops early, returning nil, if fn returns false. Otherwise reads until
+// io.EOF; any other error from r is returned.
+func (m *Matcher) FindAllReader(r io.Reader, fn func(Match) bool) error {
+	br, ok := r.(io.RuneReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	s := m.newScanner(fn)
+	offset := 0
+	for {
+		rn, size, err := br.ReadRune()
+		if err == io.EOF {
+			s.finish()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if rn == utf8.RuneError && size == 1 {
+			if !s.reset() {
+				return nil
+			}
+		} else if !s.feed(rn, offset, offset+size) {
+			return nil
+		}
+		offset += size
+	}
+}
+
+// Returns the matches in text that Replace would replace: out of every
+// match, the one that starts first, then the longest of those, then the
+// same again from where that one ended.
+func (m *Matcher) leftmostLongest(text string) []Match {
+	matches := m.FindAll(text)
+	slices.SortFunc(matches, func(a, b Match) int {
+		if a.Start != b.Start {
+			return cmp.Compare(a.Start, b.Start)
+		}
+		return cmp.Compare(b.End, a.End)
+	})
+
+	kept := matches[:0]
+	end := 0
+	for _, match := range matches {
+		if match.Start >= end {
+			kept = append(kept, match)
+			end = match.End
+		}
+	}
+	return kept
+}
+
+// Returns text with matches replaced by whatever repl gives for them;
+// repl is passed the matched part of text (not the pattern, which can
+// differ in case for folding matchers).
+//
+// Unlike FindAll, matches never overlap here. Scanning from the start of
+// text, the match that starts earliest wins, and of those, the longest
+// (leftmost-longest). Scanning then picks up where that match ended. So
+// with patterns "he", "hers" and "she", "ushers" becomes
+// "u" + repl("she") + "rs": "she" starts first, and "hers" overlaps it.
+func (m *Matcher) Replace(text string, repl func(match string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range m.leftmostLongest(text) {
+		b.WriteString(text[last:match.Start])
+		b.WriteString(repl(text[match.Start:match.End]))
+		last = match.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Same as Replace, but each match is replaced with mask, once for every
+// rune in the match. Handy for bleeping out words:
+// Censor("oh heck", '*') gives "oh ****".
+func (m *Matcher) Censor(text string, mask rune) string {
+	return m.Replace(text, func(match string) string {
+		return strings.Repeat(string(mask), utf8.RuneCountInString(match))
+	})
+}
@@ -0,0 +1,277 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode"
+)
+
+func TestMatcherFindAll(t *testing.T) {
+	m := newTrieOf("he", "she", "his", "hers", "").BuildMatcher()
+
+	matches := m.FindAll("ushers")
+	expected := []Match{
+		{"she", 1, 4},
+		{"he", 2, 4},
+		{"hers", 2, 6},
+	}
+	if !slices.Equal(matches, expected) {
+		t.Fatal("Expected", expected, "got", matches)
+	}
+
+	if matches := m.FindAll("nothing to see"); matches == nil || len(matches) != 0 {
+		t.Fatal("Expected no matches; got", matches)
+	}
+
+	// Overlapping matches of the same pattern.
+	m = newTrieOf("aa", "a").BuildMatcher()
+	expected = []Match{{"a", 0, 1}, {"aa", 0, 2}, {"a", 1, 2}, {"aa", 1, 3}, {"a", 2, 3}}
+	if matches := m.FindAll("aaa"); !slices.Equal(matches, expected) {
+		t.Fatal("Expected", expected, "got", matches)
+	}
+}
+
+func TestMatcherFindAllUnicode(t *testing.T) {
+	m := newTrieOf("café", "été", "日本").BuildMatcher()
+
+	text := "un cafété au 日本"
+	expected := []Match{
+		{"café", 3, 8},
+		{"été", 6, 11},
+		{"日本", 15, 21},
+	}
+	matches := m.FindAll(text)
+	if !slices.Equal(matches, expected) {
+		t.Fatal("Expected", expected, "got", matches)
+	}
+	for _, match := range matches {
+		if text[match.Start:match.End] != match.Pattern {
+			t.Fatalf("Expected text[%d:%d] to be %q", match.Start, match.End, match.Pattern)
+		}
+	}
+
+	// Bad bytes break up matches.
+	if matches := m.FindAll("caf\xffété"); !slices.Equal(matches, []Match{{"été", 4, 9}}) {
+		t.Fatal("Expected only été to match; got", matches)
+	}
+}
+
+func TestMatcherSnapshot(t *testing.T) {
+	trie := newTrieOf("abc")
+	m := trie.BuildMatcher()
+	trie.Put("b")
+	trie.Delete("abc")
+	if matches := m.FindAll("abc"); !slices.Equal(matches, []Match{{"abc", 0, 3}}) {
+		t.Fatal("Expected the matcher not to see changes to the trie; got", matches)
+	}
+
+	if matches := NewTrie().BuildMatcher().FindAll("abc"); len(matches) != 0 {
+		t.Fatal("Expected an empty trie to match nothing; got", matches)
+	}
+}
+
+func TestMatcherFindAllReader(t *testing.T) {
+	m := newTrieOf("café", "été", "日本", "he", "hers").BuildMatcher()
+	text := "un cafété au 日本, ushers\xffhe"
+
+	// One byte at a time, so every multi-byte rune is split across
+	// reads.
+	var matches []Match
+	err := m.FindAllReader(iotest.OneByteReader(strings.NewReader(text)), func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if expected := m.FindAll(text); !slices.Equal(matches, expected) {
+		t.Fatal("Expected", expected, "got", matches)
+	}
+
+	// Stopping early.
+	matches = nil
+	err = m.FindAllReader(strings.NewReader(text), func(match Match) bool {
+		matches = append(matches, match)
+		return len(matches) < 2
+	})
+	if err != nil || len(matches) != 2 {
+		t.Fatal("Expected to stop after 2 matches; got", matches, err)
+	}
+
+	// Errors other than io.EOF come back.
+	boom := errors.New("boom")
+	r := io.MultiReader(strings.NewReader("he"), iotest.ErrReader(boom))
+	err = m.FindAllReader(r, func(Match) bool { return true })
+	if !errors.Is(err, boom) {
+		t.Fatal("Expected the reader's error; got", err)
+	}
+}
+
+func TestMatcherFold(t *testing.T) {
+	m := newTrieOf("Damn", "HECK", "kelvin", "istanbul").BuildMatcherFold()
+
+	// U+212A KELVIN SIGN and U+0130 LATIN CAPITAL LETTER I WITH DOT ABOVE
+	// both lowercase to a single byte, so the offsets have to come from
+	// the original text.
+	text := "DAMN it, \u212aelvin, oh heck, \u0130stanbul"
+	expected := []Match{
+		{"damn", 0, 4},
+		{"kelvin", 9, 17},
+		{"heck", 22, 26},
+		{"istanbul", 28, 37},
+	}
+	matches := m.FindAll(text)
+	if !slices.Equal(matches, expected) {
+		t.Fatal("Expected", expected, "got", matches)
+	}
+	for _, match := range matches {
+		if strings.Map(unicode.ToLower, text[match.Start:match.End]) != match.Pattern {
+			t.Fatalf("Expected text[%d:%d] to be %q, ignoring case", match.Start, match.End, match.Pattern)
+		}
+	}
+
+	// Plain matchers still care about case, unless the trie doesn't.
+	if matches := newTrieOf("heck").BuildMatcher().FindAll("HECK"); len(matches) != 0 {
+		t.Fatal("Expected a plain matcher to care about case; got", matches)
+	}
+	fold := NewTrieFold()
+	fold.Put("Heck")
+	if matches := fold.BuildMatcher().FindAll("HeCk"); !slices.Equal(matches, []Match{{"heck", 0, 4}}) {
+		t.Fatal("Expected a matcher from a folding trie to ignore case; got", matches)
+	}
+}
+
+func TestMatcherReplace(t *testing.T) {
+	m := newTrieOf("he", "she", "hers", "a", "ab", "abc", "bcd").BuildMatcher()
+
+	cases := []struct {
+		text, replaced string
+	}{
+		{"ushers", "u[she]rs"},
+		{"hers", "[hers]"},
+		// Longest of the ones starting at a, even though bcd overlaps.
+		{"abcd", "[abc]d"},
+		{"xbcd abx", "x[bcd] [ab]x"},
+		{"aaa", "[a][a][a]"},
+		{"nothing", "nothing"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		replaced := m.Replace(c.text, func(match string) string {
+			return "[" + match + "]"
+		})
+		if replaced != c.replaced {
+			t.Fatalf("Replace(%q): expected %q, got %q", c.text, c.replaced, replaced)
+		}
+	}
+}
+
+func TestMatcherCensor(t *testing.T) {
+	m := newTrieOf("heck", "darn", "caf\u00e9").BuildMatcherFold()
+
+	censored := m.Censor("Oh HECK, the CAF\u00c9 is darned", '\u2588')
+	if expected := "Oh \u2588\u2588\u2588\u2588, the \u2588\u2588\u2588\u2588 is \u2588\u2588\u2588\u2588ed"; censored != expected {
+		t.Fatalf("Expected %q, got %q", expected, censored)
+	}
+}
+
+func TestMatcherWholeWords(t *testing.T) {
+	plain := newTrieOf("class", "sub", "日本").BuildMatcher()
+	m := plain.WholeWords()
+
+	cases := []struct {
+		text    string
+		matches []Match
+	}{
+		{"class", []Match{{"class", 0, 5}}},
+		{"a class.", []Match{{"class", 2, 7}}},
+		{"classic subclass", []Match{}},
+		{"class at the start, and at the end: class", []Match{{"class", 0, 5}, {"class", 36, 41}}},
+		{"class2 2class _class_", []Match{{"class", 15, 20}}},
+		{"sub-class", []Match{{"sub", 0, 3}, {"class", 4, 9}}},
+		{"\xffclass\xff", []Match{{"class", 1, 6}}},
+		// CJK characters are letters too.
+		{"日本語 日本", []Match{{"日本", 10, 16}}},
+	}
+	for _, c := range cases {
+		if matches := m.FindAll(c.text); !slices.Equal(matches, c.matches) {
+			t.Fatalf("FindAll(%q): expected %v, got %v", c.text, c.matches, matches)
+		}
+
+		var streamed []Match
+		m.FindAllReader(iotest.OneByteReader(strings.NewReader(c.text)), func(match Match) bool {
+			streamed = append(streamed, match)
+			return true
+		})
+		if !slices.Equal(streamed, c.matches) && len(streamed)+len(c.matches) != 0 {
+			t.Fatalf("FindAllReader(%q): expected %v, got %v", c.text, c.matches, streamed)
+		}
+	}
+
+	if censored := m.Censor("subclass class", '*'); censored != "subclass *****" {
+		t.Fatal("Expected Censor to only hit whole words; got", censored)
+	}
+	if matches := plain.FindAll("classic"); len(matches) != 1 {
+		t.Fatal("Expected WholeWords to leave the original matcher alone; got", matches)
+	}
+}
+
+func TestMatcherCountMatches(t *testing.T) {
+	m := newTrieOf("he", "she", "his", "hers", "a", "aa").BuildMatcher()
+	for _, text := range []string{"", "ushers", "aaaa", "she sells his shells", "nothing"} {
+		if n, expected := m.CountMatches(text), len(m.FindAll(text)); n != expected {
+			t.Fatalf("CountMatches(%q): expected %d, got %d", text, expected, n)
+		}
+		whole := m.WholeWords()
+		if n, expected := whole.CountMatches(text), len(whole.FindAll(text)); n != expected {
+			t.Fatalf("CountMatches(%q) for whole words: expected %d, got %d", text, expected, n)
+		}
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+// A few thousand words of text with a match every few words.
+func benchmarkMatcherText() (*Matcher, string) {
+	m := newTrieOf("heck", "darn", "drat", "blast", "bother").BuildMatcher()
+	text := strings.Repeat("well heck, the darned thing broke again; blast it all, bother. ", 1000)
+	return m, text
+}
+
+func BenchmarkMatcherCountMatches(b *testing.B) {
+	m, text := benchmarkMatcherText()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.CountMatches(text)
+	}
+}
+
+func BenchmarkMatcherLenFindAll(b *testing.B) {
+	m, text := benchmarkMatcherText()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = len(m.FindAll(text))
+	}
+}
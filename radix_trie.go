@@ -0,0 +1,214 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// A node in a RadixTrie. Unlike a trieNode, each node holds a whole run
+// of runes (its label), so chains of nodes that each had only one child
+// get collapsed into a single node.
+type radixNode struct {
+	// The runes on the edge leading into this node. Empty only for the
+	// root.
+	label string
+	// Keyed by the first rune of each child's label.
+	children map[rune]*radixNode
+	isEnd    bool
+}
+
+// A compressed (radix, or PATRICIA) trie. It stores the same thing as a
+// Trie, and Put, Delete, Has and HasPrefix behave the same, but it uses
+// far fewer nodes when keys have long unshared tails.
+type RadixTrie struct {
+	root radixNode
+	size int
+}
+
+// Creates a new RadixTrie for the user.
+//
+// Never returns nil.
+func NewRadixTrie() *RadixTrie {
+	return &RadixTrie{
+		root: radixNode{
+			children: map[rune]*radixNode{},
+		},
+	}
+}
+
+// Returns the first rune in s, which must not be empty.
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// Returns the length in bytes of the longest common prefix of a and b
+// that ends on a rune boundary.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	// Back off to the start of the rune we stopped in the middle of.
+	for n > 0 && n < len(a) && !utf8.RuneStart(a[n]) {
+		n--
+	}
+	return n
+}
+
+// Puts a full string of runes into the given RadixTrie.
+//
+// Returns a nil error on success. Currently, failure only happens if s
+// has an invalid utf-8 sequence in it, in which case the error is
+// ErrInvalidUTF8.
+func (t *RadixTrie) Put(s string) error {
+	if !utf8.ValidString(s) {
+		return ErrInvalidUTF8
+	}
+
+	node := &t.root
+	for len(s) != 0 {
+		r := firstRune(s)
+		child, ok := node.children[r]
+		if !ok {
+			node.children[r] = &radixNode{
+				label:    s,
+				children: map[rune]*radixNode{},
+			}
+			node = node.children[r]
+			break
+		}
+
+		n := commonPrefixLen(child.label, s)
+		if n < len(child.label) {
+			// s splits child's label; put a new node at the split.
+			mid := &radixNode{
+				label:    child.label[:n],
+				children: map[rune]*radixNode{},
+			}
+			child.label = child.label[n:]
+			mid.children[firstRune(child.label)] = child
+			node.children[r] = mid
+			child = mid
+		}
+		node = child
+		s = s[n:]
+	}
+
+	if !node.isEnd {
+		node.isEnd = true
+		t.size++
+	}
+	return nil
+}
+
+// Walks down to the node whose label s ends in. Returns the node, its
+// parent, and how many bytes of the node's label s covers. If s isn't
+// in the trie (or utf8 decode error), nil is returned for the node.
+func (t *RadixTrie) searchNode(s string) (node, parent *radixNode, covered int) {
+	node = &t.root
+	for len(s) != 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			return nil, nil, 0
+		}
+		child, ok := node.children[r]
+		if !ok {
+			return nil, nil, 0
+		}
+		if len(s) < len(child.label) {
+			if !strings.HasPrefix(child.label, s) || !utf8.ValidString(s) {
+				return nil, nil, 0
+			}
+			return child, node, len(s)
+		}
+		if !strings.HasPrefix(s, child.label) {
+			return nil, nil, 0
+		}
+		parent, node = node, child
+		s = s[len(child.label):]
+	}
+	return node, parent, len(node.label)
+}
+
+// Searches for the given string in the trie.
+//
+// Returns true on found, false on not found (or error decoding string)
+func (t *RadixTrie) Has(s string) bool {
+	node, _, covered := t.searchNode(s)
+	return node != nil && covered == len(node.label) && node.isEnd
+}
+
+// Searches for the given string in the trie. This will return true if
+// there is either a full string or just the prefix of a string that
+// matches the input.
+//
+// Returns true on found, false on not found (or error decoding string).
+func (t *RadixTrie) HasPrefix(s string) bool {
+	node, _, _ := t.searchNode(s)
+	return node != nil && (node.isEnd || len(node.children) != 0)
+}
+
+// Folds node's only child into it, so the two labels become one.
+func (t *radixNode) mergeChild() {
+	for _, child := range t.children {
+		t.label += child.label
+		t.children = child.children
+		t.isEnd = child.isEnd
+	}
+}
+
+// Removes the given string from the trie, merging nodes back together
+// where it's no longer needed to keep them apart.
+func (t *RadixTrie) Delete(s string) {
+	node, parent, covered := t.searchNode(s)
+	if node == nil || covered != len(node.label) || !node.isEnd {
+		return
+	}
+	node.isEnd = false
+	t.size--
+
+	if node == &t.root {
+		return
+	}
+	switch len(node.children) {
+	case 0:
+		delete(parent.children, firstRune(node.label))
+		if parent != &t.root && !parent.isEnd && len(parent.children) == 1 {
+			parent.mergeChild()
+		}
+	case 1:
+		node.mergeChild()
+	}
+}
+
+// Returns the number of strings stored in the trie.
+func (t *RadixTrie) Len() int {
+	return t.size
+}
+
+// Returns the number of nodes in the subtree rooted at this node,
+// including this node.
+func (t *radixNode) countNodes() int {
+	n := 1
+	for _, child := range t.children {
+		n += child.countNodes()
+	}
+	return n
+}
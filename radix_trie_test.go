@@ -0,0 +1,164 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestRadixTrieMatchesTrie(t *testing.T) {
+	rand.Seed(0) // Arbitrary seed
+
+	trie, radix := NewTrie(), NewRadixTrie()
+	// A small alphabet (with a multi-byte rune) so keys share lots of
+	// prefixes and splits/merges happen often.
+	alphabet := []rune("abé")
+	randString := func() string {
+		buf := make([]rune, rand.Intn(6))
+		for i := range buf {
+			buf[i] = alphabet[rand.Intn(len(alphabet))]
+		}
+		return string(buf)
+	}
+
+	for i := 0; i < 5000; i++ {
+		s := randString()
+		if rand.Intn(3) == 0 {
+			trie.Delete(s)
+			radix.Delete(s)
+		} else {
+			trie.Put(s)
+			radix.Put(s)
+		}
+
+		q := randString()
+		if trie.Has(q) != radix.Has(q) {
+			t.Fatalf("Has(%q) differs after op %d", q, i)
+		}
+		if trie.HasPrefix(q) != radix.HasPrefix(q) {
+			t.Fatalf("HasPrefix(%q) differs after op %d", q, i)
+		}
+		if trie.Len() != radix.Len() {
+			t.Fatal("Len differs after op", i)
+		}
+	}
+}
+
+func TestRadixTrieSplitAndMerge(t *testing.T) {
+	radix := NewRadixTrie()
+	radix.Put("romane")
+	radix.Put("romanus")
+	radix.Put("romulus")
+	radix.Put("rom")
+
+	for _, n := range []string{"romane", "romanus", "romulus", "rom"} {
+		if !radix.Has(n) {
+			t.Fatal("Expected to find", n)
+		}
+	}
+	for _, n := range []string{"r", "roma", "romanu", "romul"} {
+		if radix.Has(n) || !radix.HasPrefix(n) {
+			t.Fatal("Expected to only find prefix", n)
+		}
+	}
+	if radix.HasPrefix("romx") || radix.HasPrefix("romanez") {
+		t.Fatal("Found a prefix that was never put")
+	}
+
+	// root, rom, an, e, us, ulus
+	if n := radix.root.countNodes(); n != 6 {
+		t.Fatal("Expected 6 nodes; got", n)
+	}
+
+	radix.Delete("romanus")
+	radix.Delete("rom")
+	// root, rom, ane, ulus
+	if n := radix.root.countNodes(); n != 4 {
+		t.Fatal("Expected deletes to merge nodes back down to 4; got", n)
+	}
+	radix.Delete("romulus")
+	// root, romane
+	if n := radix.root.countNodes(); n != 2 {
+		t.Fatal("Expected deletes to merge nodes back down to 2; got", n)
+	}
+	if !radix.Has("romane") || radix.Len() != 1 {
+		t.Fatal("Expected only romane to remain")
+	}
+
+	if err := radix.Put("bad\xff"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+// Builds the same dataset as BenchmarkLargeTrieSearch with both kinds of
+// trie, reporting how many nodes and bytes each one takes.
+func benchmarkLargeBuild(b *testing.B, build func([]string) int) {
+	const NUM_STRINGS = 1000000
+	const STR_LEN = 10
+	const NUM_CHRS = 94
+	const OFFSET = 32
+
+	rand.Seed(0) // Arbitrary seed
+
+	strings := make([]string, NUM_STRINGS)
+	buf := make([]rune, STR_LEN)
+	for i := range strings {
+		for x := range buf {
+			buf[x] = rune(rand.Int31n(NUM_CHRS) + OFFSET)
+		}
+		strings[i] = string(buf)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	b.ReportAllocs()
+	b.ResetTimer()
+	nodes := 0
+	for i := 0; i < b.N; i++ {
+		nodes = build(strings)
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(nodes), "nodes")
+	b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gcs/op")
+}
+
+func BenchmarkLargeTrieBuild(b *testing.B) {
+	benchmarkLargeBuild(b, func(strings []string) int {
+		trie := NewTrie()
+		for _, s := range strings {
+			trie.Put(s)
+		}
+		return trie.root.countNodes()
+	})
+}
+
+func BenchmarkLargeRadixTrieBuild(b *testing.B) {
+	benchmarkLargeBuild(b, func(strings []string) int {
+		trie := NewRadixTrie()
+		for _, s := range strings {
+			trie.Put(s)
+		}
+		return trie.root.countNodes()
+	})
+}
@@ -0,0 +1,98 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import "unicode/utf8"
+
+// A set of strings organized by their ends rather than their starts, for
+// suffix queries like "which stored domain is the longest suffix of
+// www.example.com?". Strings are stored rune by rune, reversed, in a
+// normal Trie; ReverseTrie takes care of the reversing, so everything
+// going in and coming out is the right way around.
+type ReverseTrie struct {
+	trie *Trie
+}
+
+// Creates a new, empty ReverseTrie for the user.
+//
+// Never returns nil.
+func NewReverseTrie() *ReverseTrie {
+	return &ReverseTrie{trie: NewTrie()}
+}
+
+// Returns the runes of s in reverse order, as a string. Reading stops (at
+// the front of s) at the last invalid utf8 sequence, so only the valid
+// tail of s is reversed.
+func reverseRunes(s string) string {
+	runes := make([]rune, 0, len(s))
+	for len(s) != 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+		runes = append(runes, r)
+		s = s[:len(s)-size]
+	}
+	return string(runes)
+}
+
+// Adds s, same as Trie.Put.
+//
+// Returns ErrInvalidUTF8 if s isn't valid utf8, in which case nothing is
+// stored.
+func (rt *ReverseTrie) Put(s string) error {
+	if !utf8.ValidString(s) {
+		return ErrInvalidUTF8
+	}
+	return rt.trie.Put(reverseRunes(s))
+}
+
+// Searches for the given string, same as Trie.Has.
+func (rt *ReverseTrie) Has(s string) bool {
+	return utf8.ValidString(s) && rt.trie.Has(reverseRunes(s))
+}
+
+// Removes the given string, same as Trie.Delete.
+func (rt *ReverseTrie) Delete(s string) {
+	if utf8.ValidString(s) {
+		rt.trie.Delete(reverseRunes(s))
+	}
+}
+
+// Reports whether any stored string ends with suffix; the mirror image
+// of Trie.HasPrefix.
+func (rt *ReverseTrie) HasSuffix(suffix string) bool {
+	return utf8.ValidString(suffix) && rt.trie.HasPrefix(reverseRunes(suffix))
+}
+
+// Finds the longest string stored in the ReverseTrie that is a suffix of
+// s; the mirror image of Trie.LongestPrefixOf. Only the valid trailing
+// part of s, after any invalid utf8, is considered.
+//
+// Returns "" and false if no stored string is a suffix of s.
+func (rt *ReverseTrie) LongestSuffixOf(s string) (string, bool) {
+	found, ok := rt.trie.LongestPrefixOf(reverseRunes(s))
+	if !ok {
+		return "", false
+	}
+	return reverseRunes(found), true
+}
+
+// Returns the number of strings in the ReverseTrie.
+func (rt *ReverseTrie) Len() int {
+	return rt.trie.Len()
+}
@@ -0,0 +1,65 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReverseTrie(t *testing.T) {
+	rt := NewReverseTrie()
+	for _, n := range []string{".com", "example.com", ".org", "日本.jp", "é"} {
+		if err := rt.Put(n); err != nil {
+			t.Fatal("Unexpected error putting", n, err)
+		}
+	}
+	if err := rt.Put("bad\xff.com"); !errors.Is(err, ErrInvalidUTF8) || rt.Len() != 5 {
+		t.Fatal("Expected ErrInvalidUTF8 and nothing stored; got", err)
+	}
+
+	if !rt.Has("example.com") || rt.Has("moc.") || rt.Has("com") {
+		t.Fatal("Expected Has to match whole strings the right way around")
+	}
+	if !rt.HasSuffix("ple.com") || !rt.HasSuffix("本.jp") || rt.HasSuffix(".net") {
+		t.Fatal("Expected HasSuffix to match the ends of stored strings")
+	}
+
+	cases := []struct {
+		s, expected string
+		ok          bool
+	}{
+		{"www.example.com", "example.com", true},
+		{"other.com", ".com", true},
+		{"東京日本.jp", "日本.jp", true},
+		{"café", "é", true},
+		{"example.net", "", false},
+		// Only the part after the bad byte counts.
+		{"x\xffexample.com", "example.com", true},
+		{"example.co\xffm", "", false},
+	}
+	for _, c := range cases {
+		if s, ok := rt.LongestSuffixOf(c.s); s != c.expected || ok != c.ok {
+			t.Fatalf("LongestSuffixOf(%q): expected (%q, %v); got (%q, %v)", c.s, c.expected, c.ok, s, ok)
+		}
+	}
+
+	rt.Delete("example.com")
+	if s, _ := rt.LongestSuffixOf("www.example.com"); s != ".com" || rt.Len() != 4 {
+		t.Fatal("Expected .com once example.com is gone; got", s)
+	}
+}
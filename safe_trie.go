@@ -0,0 +1,76 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"sync"
+)
+
+// A Trie that's safe to use from multiple goroutines at once. Writes
+// (Put, Delete) take an exclusive lock, while reads (Has, HasPrefix,
+// Keys) can happen concurrently with each other.
+type SafeTrie struct {
+	// Not embedded, so that the unguarded Trie methods don't get
+	// promoted onto SafeTrie.
+	trie *Trie
+	lock sync.RWMutex
+}
+
+// Creates a new, empty SafeTrie.
+//
+// Never returns nil.
+func NewSafeTrie() *SafeTrie {
+	return &SafeTrie{
+		trie: NewTrie(),
+	}
+}
+
+// Same as Trie.Put, under the write lock.
+func (t *SafeTrie) Put(s string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.trie.Put(s)
+}
+
+// Same as Trie.Delete, under the write lock.
+func (t *SafeTrie) Delete(s string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.trie.Delete(s)
+}
+
+// Same as Trie.Has, under the read lock.
+func (t *SafeTrie) Has(s string) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.trie.Has(s)
+}
+
+// Same as Trie.HasPrefix, under the read lock.
+func (t *SafeTrie) HasPrefix(s string) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.trie.HasPrefix(s)
+}
+
+// Same as Trie.Keys, under the read lock. The result is a snapshot of
+// the trie at the time of the call; later writes don't show up in it.
+func (t *SafeTrie) Keys() []string {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.trie.Keys()
+}
@@ -0,0 +1,71 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSafeTrie(t *testing.T) {
+	trie := NewSafeTrie()
+	trie.Put("foo")
+	trie.Put("foobar")
+	trie.Delete("foo")
+
+	if trie.Has("foo") || !trie.Has("foobar") || !trie.HasPrefix("foo") {
+		t.Fatal("Unexpected contents:", trie.Keys())
+	}
+	if keys := trie.Keys(); !slices.Equal(keys, []string{"foobar"}) {
+		t.Fatal("Expected keys [foobar]; got", keys)
+	}
+}
+
+// Meant to be run with -race.
+func TestSafeTrieConcurrent(t *testing.T) {
+	const writers = 4
+	const perWriter = 200
+
+	trie := NewSafeTrie()
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				trie.Put(fmt.Sprintf("%d-%d", w, i))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				trie.Has(fmt.Sprintf("%d-%d", w, i))
+				trie.HasPrefix(fmt.Sprint(w))
+				if i%50 == 0 {
+					trie.Keys()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(trie.Keys()); n != writers*perWriter {
+		t.Fatal("Expected", writers*perWriter, "keys; got", n)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+)
+
+// A node in a SeqTrie.
+type seqTrieNode[T comparable] struct {
+	children map[T]*seqTrieNode[T]
+	// The keys of children, in the order they were added. T is only
+	// comparable, not ordered, so this is what keeps enumeration
+	// deterministic.
+	order []T
+	isEnd bool
+}
+
+// A trie over sequences of any comparable type, e.g. token IDs from a
+// tokenizer. It works the same way a Trie does over the runes of a
+// string; a Trie is more or less a SeqTrie[rune] that takes strings.
+type SeqTrie[T comparable] struct {
+	root seqTrieNode[T]
+	size int
+}
+
+// Creates a new SeqTrie for the user.
+//
+// Never returns nil.
+func NewSeqTrie[T comparable]() *SeqTrie[T] {
+	return &SeqTrie[T]{}
+}
+
+// Returns the node for the last element of seq, or nil if it isn't
+// there. The empty sequence maps to the root.
+func (t *SeqTrie[T]) searchNode(seq []T) *seqTrieNode[T] {
+	current := &t.root
+	for _, e := range seq {
+		current = current.children[e]
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// Puts a sequence into the trie. The trie doesn't keep a reference to
+// seq, so the caller is free to reuse it.
+func (t *SeqTrie[T]) Put(seq []T) {
+	node := &t.root
+	for _, e := range seq {
+		child := node.children[e]
+		if child == nil {
+			if node.children == nil {
+				node.children = map[T]*seqTrieNode[T]{}
+			}
+			child = &seqTrieNode[T]{}
+			node.children[e] = child
+			node.order = append(node.order, e)
+		}
+		node = child
+	}
+	if !node.isEnd {
+		node.isEnd = true
+		t.size++
+	}
+}
+
+// Searches for the given sequence in the trie.
+func (t *SeqTrie[T]) Has(seq []T) bool {
+	node := t.searchNode(seq)
+	return node != nil && node.isEnd
+}
+
+// Searches for the given sequence in the trie. This will return true if
+// there is either a full sequence or just the prefix of a sequence that
+// matches the input.
+func (t *SeqTrie[T]) HasPrefix(seq []T) bool {
+	node := t.searchNode(seq)
+	return node != nil && (node.isEnd || len(node.children) != 0)
+}
+
+// Removes the given sequence from the trie, along with any nodes that
+// only existed to hold it. Prefixes of other sequences stay put.
+func (t *SeqTrie[T]) Delete(seq []T) {
+	path := []*seqTrieNode[T]{&t.root}
+	for _, e := range seq {
+		next := path[len(path)-1].children[e]
+		if next == nil {
+			return
+		}
+		path = append(path, next)
+	}
+
+	node := path[len(path)-1]
+	if !node.isEnd {
+		return
+	}
+	node.isEnd = false
+	t.size--
+
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].isEnd || len(path[i].children) != 0 {
+			break
+		}
+		parent, e := path[i-1], seq[i-1]
+		delete(parent.children, e)
+		parent.order = slices.DeleteFunc(parent.order, func(o T) bool { return o == e })
+	}
+}
+
+// Returns the number of sequences stored in the trie.
+func (t *SeqTrie[T]) Len() int {
+	return t.size
+}
+
+// Appends every sequence that ends at or below this node to out. prefix
+// holds the elements on the path from the root down to this node.
+func (t *seqTrieNode[T]) collect(prefix []T, out [][]T) [][]T {
+	if t.isEnd {
+		out = append(out, slices.Clone(prefix))
+	}
+	for _, e := range t.order {
+		out = t.children[e].collect(append(prefix, e), out)
+	}
+	return out
+}
+
+// Returns every sequence stored in the trie that starts with prefix,
+// including prefix itself if it was stored. Since T needn't be ordered,
+// sequences come out depth-first with each node's children in the order
+// they were first added, rather than sorted.
+//
+// Never returns nil; if nothing starts with prefix, an empty slice is
+// returned.
+func (t *SeqTrie[T]) KeysWithPrefix(prefix []T) [][]T {
+	node := t.searchNode(prefix)
+	if node == nil {
+		return [][]T{}
+	}
+	return node.collect(slices.Clone(prefix), [][]T{})
+}
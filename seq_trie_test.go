@@ -0,0 +1,132 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSeqTrie(t *testing.T) {
+	trie := NewSeqTrie[int]()
+	putIn := [][]int{{1, 2, 3}, {4, 5}, {6, 7, 8, 9}, {1, 3, 12}, {13, 12, 16}}
+	notPutIn := [][]int{{1, 2, 4}, {4, 6}, {6, 8, 7, 9, 10}, {1, 4, 12}, {6, 9, 13}}
+
+	for _, n := range putIn {
+		trie.Put(n)
+		if !trie.Has(n) || !trie.HasPrefix(n) {
+			t.Fatal("Expected to find", n)
+		}
+	}
+	for _, n := range putIn {
+		for i := len(n) - 1; i > 0; i-- {
+			if trie.Has(n[:i]) || !trie.HasPrefix(n[:i]) {
+				t.Fatal("Expected to find only the prefix", n[:i])
+			}
+		}
+	}
+	for _, n := range notPutIn {
+		if trie.Has(n) || trie.HasPrefix(n) {
+			t.Fatal("Expected not to find", n)
+		}
+	}
+	if trie.Len() != len(putIn) {
+		t.Fatal("Expected Len", len(putIn), "got", trie.Len())
+	}
+
+	trie.Delete([]int{1, 2, 3})
+	if trie.Has([]int{1, 2, 3}) || trie.HasPrefix([]int{1, 2}) || !trie.Has([]int{1, 3, 12}) {
+		t.Fatal("Unexpected contents after delete")
+	}
+	trie.Delete([]int{1})
+	if trie.Len() != len(putIn)-1 {
+		t.Fatal("Expected deleting a prefix to do nothing; Len is", trie.Len())
+	}
+}
+
+func TestSeqTrieKeysWithPrefix(t *testing.T) {
+	trie := NewSeqTrie[string]()
+	trie.Put([]string{"the", "cat"})
+	trie.Put([]string{"the", "dog"})
+	trie.Put([]string{"the"})
+	trie.Put([]string{"a", "dog"})
+
+	prefix := []string{"the"}
+	keys := trie.KeysWithPrefix(prefix)
+	expected := [][]string{{"the"}, {"the", "cat"}, {"the", "dog"}}
+	if !slices.EqualFunc(keys, expected, slices.Equal) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+
+	// Results don't alias each other or the caller's prefix.
+	keys[1][1] = "bird"
+	prefix[0] = "x"
+	if !slices.Equal(keys[2], []string{"the", "dog"}) || !trie.Has([]string{"the", "cat"}) {
+		t.Fatal("Expected results to be independent copies")
+	}
+
+	if keys := trie.KeysWithPrefix([]string{"zebra"}); keys == nil || len(keys) != 0 {
+		t.Fatal("Expected empty, non-nil keys; got", keys)
+	}
+	if keys := trie.KeysWithPrefix(nil); len(keys) != 4 {
+		t.Fatal("Expected every key for the empty prefix; got", keys)
+	}
+}
+
+// A SeqTrie[rune] should agree with a Trie on the same strings.
+func TestSeqTrieMatchesTrie(t *testing.T) {
+	rand.Seed(0) // Arbitrary seed
+
+	trie, seq := NewTrie(), NewSeqTrie[rune]()
+	randString := func() string {
+		buf := make([]rune, rand.Intn(5))
+		for i := range buf {
+			buf[i] = rune('a' + rand.Intn(3))
+		}
+		return string(buf)
+	}
+
+	for i := 0; i < 2000; i++ {
+		s := randString()
+		if rand.Intn(3) == 0 {
+			trie.Delete(s)
+			seq.Delete([]rune(s))
+		} else {
+			trie.Put(s)
+			seq.Put([]rune(s))
+		}
+
+		q := randString()
+		if trie.Has(q) != seq.Has([]rune(q)) || trie.HasPrefix(q) != seq.HasPrefix([]rune(q)) {
+			t.Fatalf("Trie and SeqTrie disagree on %q after op %d", q, i)
+		}
+		if trie.Len() != seq.Len() {
+			t.Fatal("Len differs after op", i)
+		}
+	}
+
+	// Same keys, if not in the same order.
+	var seqKeys []string
+	for _, k := range seq.KeysWithPrefix(nil) {
+		seqKeys = append(seqKeys, string(k))
+	}
+	slices.Sort(seqKeys)
+	if !slices.Equal(seqKeys, trie.Keys()) {
+		t.Fatal("Expected", trie.Keys(), "got", seqKeys)
+	}
+}
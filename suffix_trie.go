@@ -0,0 +1,109 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// A set of strings that can be searched by substring, not just by prefix.
+// Every suffix of every string is stored in a trie, so a substring of a
+// stored string is a prefix of one of those suffixes.
+//
+// That costs a lot more memory than a Trie: a string n runes long puts in
+// n+1 suffixes, so a SuffixTrie can take on the order of n² nodes per
+// string (a few long strings with little in common are the worst case).
+// Only use one when substring queries are actually needed.
+type SuffixTrie struct {
+	// The strings as they were put in.
+	words *Trie
+	// Every suffix of every word, including the empty one, mapped to the
+	// words it's a suffix of.
+	suffixes *TrieMap[[]string]
+}
+
+// Creates a new, empty SuffixTrie for the user.
+//
+// Never returns nil.
+func NewSuffixTrie() *SuffixTrie {
+	return &SuffixTrie{
+		words:    NewTrie(),
+		suffixes: NewTrieMap[[]string](),
+	}
+}
+
+// Adds s and all of its suffixes. Putting a string that's already there
+// does nothing.
+//
+// Returns ErrInvalidUTF8 if s isn't valid utf8, in which case nothing is
+// added.
+func (st *SuffixTrie) Put(s string) error {
+	added, err := st.words.PutNew(s)
+	if err != nil || !added {
+		return err
+	}
+	for i := 0; ; {
+		st.suffixes.Update(s[i:], func(words []string, _ bool) []string {
+			return append(words, s)
+		})
+		if i == len(s) {
+			return nil
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+}
+
+// Returns the number of strings in the SuffixTrie (not the number of
+// suffixes).
+func (st *SuffixTrie) Len() int {
+	return st.words.Len()
+}
+
+// Searches for the given string, same as Trie.Has.
+func (st *SuffixTrie) Has(s string) bool {
+	return st.words.Has(s)
+}
+
+// Reports whether any stored string contains sub. Every stored string
+// contains "", so that's true unless the SuffixTrie is empty.
+//
+// Returns false if sub isn't valid utf8.
+func (st *SuffixTrie) ContainsSubstring(sub string) bool {
+	return st.suffixes.searchNode(sub) != nil && st.Len() != 0
+}
+
+// Returns every stored string that contains sub, in the same order as
+// Trie.Keys.
+//
+// Never returns nil; if nothing contains sub (or sub isn't valid utf8),
+// an empty slice is returned.
+func (st *SuffixTrie) KeysContaining(sub string) []string {
+	found := map[string]bool{}
+	for _, words := range st.suffixes.PrefixValues(sub) {
+		for _, w := range words {
+			found[w] = true
+		}
+	}
+	out := make([]string, 0, len(found))
+	for w := range found {
+		out = append(out, w)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,62 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSuffixTrie(t *testing.T) {
+	st := NewSuffixTrie()
+	if st.ContainsSubstring("") || len(st.KeysContaining("")) != 0 {
+		t.Fatal("Expected an empty SuffixTrie to contain nothing")
+	}
+
+	words := []string{"banana", "bandana", "cabana", "été", "", "banana"}
+	for _, w := range words {
+		if err := st.Put(w); err != nil {
+			t.Fatal("Unexpected error putting", w, err)
+		}
+	}
+	if err := st.Put("bad\xff"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+	if st.Len() != 5 || !st.Has("cabana") || st.Has("ana") {
+		t.Fatal("Expected exactly the 5 distinct words to be stored")
+	}
+
+	// Compare against a plain search of every word.
+	for _, sub := range []string{"", "ana", "nan", "band", "cab", "a", "t", "té", "x", "anab", "ana\xff"} {
+		want := []string{}
+		if !strings.Contains(sub, "\xff") {
+			for _, w := range []string{"", "banana", "bandana", "cabana", "été"} {
+				if strings.Contains(w, sub) {
+					want = append(want, w)
+				}
+			}
+		}
+		if got := st.KeysContaining(sub); !slices.Equal(got, want) {
+			t.Fatalf("KeysContaining(%q): expected %q, got %q", sub, want, got)
+		}
+		if st.ContainsSubstring(sub) != (len(want) != 0) {
+			t.Fatalf("ContainsSubstring(%q): expected %v", sub, len(want) != 0)
+		}
+	}
+}
@@ -17,10 +17,30 @@
 package gollections
 
 import (
+	"cmp"
+	"container/list"
 	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
 	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
+// Returned when a string handed to the trie isn't valid utf8.
+var ErrInvalidUTF8 = errors.New("Invalid utf8 in string")
+
+// Returned when a string with non-ASCII runes in it is put into a trie
+// made by NewTrieASCII.
+var ErrNotASCII = errors.New("Non-ASCII rune in string")
+
+// Returned when a string longer than the limit set by NewTrieMaxKeyRunes
+// is put into the trie.
+var ErrKeyTooLong = errors.New("String has too many runes")
+
 // The root and elements of a trie.
 //
 // Each TrieNode is associated with a rune. For example:
@@ -32,9 +52,185 @@ import (
 //
 // In this case, a, b, c, d, e, and f are all TrieNodes.
 type trieNode struct {
-	children map[rune]*trieNode
-	value    rune
-	isEnd    bool
+	// Most nodes only have a handful of children, and a map is a lot of
+	// memory (and a lot of pointer chasing) for a handful of children.
+	// So children live in small, sorted by rune, until there are more
+	// than maxSmallChildren of them. Past that, they all move to big,
+	// and small is left nil.
+	small []trieEdge
+	big   map[rune]*trieNode
+	// Tries made by NewTrieASCII skip all of the above and index their
+	// children directly by rune. Like small, it stays nil until the node
+	// gets a child, and goes back to nil when the last one is removed, so
+	// leaves don't carry an empty array around.
+	ascii *asciiChildren
+	value rune
+	isEnd bool
+	// The node this one hangs off of; nil for the root.
+	parent *trieNode
+	// How many times the string ending here was added; see Trie.Add.
+	// Always > 0 if isEnd is set, and 0 otherwise.
+	count int
+	// How many strings end at this node or below it, kept up to date by
+	// markEnd and friends so CountWithPrefix doesn't have to look.
+	ends int
+}
+
+// The children of a node in a trie made by NewTrieASCII.
+type asciiChildren struct {
+	nodes [utf8.RuneSelf]*trieNode
+	n     int
+}
+
+// A child of a trieNode, and the rune that leads to it.
+type trieEdge struct {
+	r    rune
+	node *trieNode
+}
+
+// The most children a trieNode will keep in its small slice.
+const maxSmallChildren = 8
+
+// Returns the child for r, or nil if there isn't one.
+func (t *trieNode) child(r rune) *trieNode {
+	if t.ascii != nil {
+		if uint32(r) < utf8.RuneSelf {
+			return t.ascii.nodes[r]
+		}
+		return nil
+	}
+	if t.big != nil {
+		return t.big[r]
+	}
+	for _, e := range t.small {
+		if e.r >= r {
+			if e.r == r {
+				return e.node
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// Returns how many children this node has.
+func (t *trieNode) numChildren() int {
+	if t.ascii != nil {
+		return t.ascii.n
+	}
+	if t.big != nil {
+		return len(t.big)
+	}
+	return len(t.small)
+}
+
+// Makes node the child for r, replacing any child that was there.
+//
+// In an ASCII node, r must be ASCII.
+func (t *trieNode) setChild(r rune, node *trieNode) {
+	if t.ascii != nil {
+		if t.ascii.nodes[r] == nil {
+			t.ascii.n++
+		}
+		t.ascii.nodes[r] = node
+		return
+	}
+	if t.big != nil {
+		t.big[r] = node
+		return
+	}
+
+	i, found := slices.BinarySearchFunc(t.small, r, func(e trieEdge, r rune) int {
+		return cmp.Compare(e.r, r)
+	})
+	if found {
+		t.small[i].node = node
+		return
+	}
+	if len(t.small) < maxSmallChildren {
+		t.small = slices.Insert(t.small, i, trieEdge{r, node})
+		return
+	}
+
+	t.big = make(map[rune]*trieNode, len(t.small)+1)
+	for _, e := range t.small {
+		t.big[e.r] = e.node
+	}
+	t.big[r] = node
+	t.small = nil
+}
+
+// Removes the child for r, if there is one. A node whose children were
+// moved to big stays that way, even if it shrinks.
+func (t *trieNode) removeChild(r rune) {
+	if t.ascii != nil {
+		if uint32(r) < utf8.RuneSelf && t.ascii.nodes[r] != nil {
+			t.ascii.nodes[r] = nil
+			t.ascii.n--
+			if t.ascii.n == 0 {
+				t.ascii = nil
+			}
+		}
+		return
+	}
+	if t.big != nil {
+		delete(t.big, r)
+		return
+	}
+	for i, e := range t.small {
+		if e.r == r {
+			t.small = slices.Delete(t.small, i, i+1)
+			return
+		}
+	}
+}
+
+// Removes every child of this node.
+func (t *trieNode) clearChildren() {
+	t.ascii = nil
+	t.small = nil
+	t.big = nil
+}
+
+// Returns an iterator over this node's children, in no particular order.
+func (t *trieNode) children() iter.Seq2[rune, *trieNode] {
+	return func(yield func(rune, *trieNode) bool) {
+		if t.ascii != nil {
+			for r, node := range t.ascii.nodes {
+				if node != nil && !yield(rune(r), node) {
+					return
+				}
+			}
+			return
+		}
+		if t.big != nil {
+			for r, node := range t.big {
+				if !yield(r, node) {
+					return
+				}
+			}
+			return
+		}
+		for _, e := range t.small {
+			if !yield(e.r, e.node) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over this node's children, in ascending order.
+func (t *trieNode) sortedChildren() iter.Seq2[rune, *trieNode] {
+	if t.big == nil {
+		return t.children()
+	}
+	return func(yield func(rune, *trieNode) bool) {
+		for _, r := range t.sortedRunes() {
+			if !yield(r, t.big[r]) {
+				return
+			}
+		}
+	}
 }
 
 type Trie struct {
@@ -42,13 +238,37 @@ type Trie struct {
 	// have no clue how to cast from (type Integer int) *Integer ->
 	// *int
 	root trieNode
+	// Number of strings stored in the trie, so Len doesn't need to
+	// walk every node.
+	size int
+	// If set, every rune is lowercased on the way in. See NewTrieFold.
+	fold bool
+	// If set, every string is put in NFC on the way in. See
+	// NewTrieNormalized.
+	normalize bool
+	// If set, only ASCII strings may be put in, and every node is an
+	// ASCII node. See NewTrieASCII.
+	ascii bool
+	// If > 0, the most strings the trie holds at once. See
+	// NewTrieCapped.
+	capacity int
+	// For capped tries, every end of a string in the order they were
+	// put, oldest first, and where each one is in that list.
+	order   *list.List
+	orderOf map[*trieNode]*list.Element
+	// Where new nodes come from, if not the heap. See NewTrieArena.
+	arena *nodeArena
+	// Called on every change to what's in the trie. See OnChange.
+	observers []func(op Op, word string)
+	// If > 0, the most runes a string put in the trie may have. See
+	// NewTrieMaxKeyRunes.
+	maxKeyRunes int
 }
 
 // Makes a trie node for me.
 func newTrieNode(r rune) *trieNode {
 	return &trieNode{
-		children: map[rune]*trieNode{},
-		value:    r,
+		value: r,
 	}
 }
 
@@ -56,10 +276,8 @@ func newTrieNode(r rune) *trieNode {
 //
 // Never returns nil.
 func NewTrie() *Trie {
-	// TODO: Maybe create children on demand?
 	child := trieNode{
-		children: map[rune]*trieNode{},
-		value:    utf8.RuneError,
+		value: utf8.RuneError,
 	}
 
 	return &Trie{
@@ -67,145 +285,1380 @@ func NewTrie() *Trie {
 	}
 }
 
-// Returns the trieNode of the last char in the given string, and its parent.
-// If not found (or utf8 decode error), nil is returned for both.
-func (t *Trie) searchNode(s string) *trieNode {
-	if len(s) == 0 {
-		return nil
+// Creates a new case-insensitive Trie for the user. Every string passed
+// to it is folded with unicode.ToLower before being stored or looked
+// up, so "Apple", "apple" and "APPLE" are all the same key. Strings
+// coming out of the trie (e.g. from Keys) are in folded form.
+//
+// Never returns nil.
+func NewTrieFold() *Trie {
+	t := NewTrie()
+	t.fold = true
+	return t
+}
+
+// Creates a new Trie for the user that puts every string passed to it
+// into Unicode Normalization Form C before storing or looking it up.
+// That way, "é" written as one code point and "e" followed by a
+// combining accent are the same key. Strings coming out of the trie
+// (e.g. from Keys) are in NFC.
+//
+// Never returns nil.
+func NewTrieNormalized() *Trie {
+	t := NewTrie()
+	t.normalize = true
+	return t
+}
+
+// Creates a new Trie for the user that only holds ASCII strings. Each
+// node indexes its children with a flat array rather than searching for
+// them, which makes lookups faster at the cost of memory: every node with
+// children carries a 128-pointer array (1KiB on 64-bit machines). That's a good
+// trade for small or densely branching tries, but for very large, sparse
+// ones the extra memory traffic can make it slower than a plain Trie
+// (see BenchmarkLargeTrieSearchASCII).
+//
+// Putting a string with a rune >= 128 in it fails with ErrNotASCII.
+// Looking one up is fine; it just won't be found.
+//
+// Never returns nil.
+func NewTrieASCII() *Trie {
+	t := NewTrie()
+	t.ascii = true
+	return t
+}
+
+// Creates a new Trie for the user that rejects strings with more than
+// max runes in them, so a few huge keys can't eat up all the memory.
+// Putting one fails with ErrKeyTooLong, and stores nothing. The runes are
+// counted as the string is put in, not in a pass of their own. Strings
+// are measured in the form they're stored in (see NewTrieFold).
+//
+// A max < 1 means there's no limit, same as NewTrie.
+//
+// Never returns nil.
+func NewTrieMaxKeyRunes(max int) *Trie {
+	t := NewTrie()
+	t.maxKeyRunes = max
+	return t
+}
+
+// Returns a new, empty trie with the same options (folding,
+// normalization, etc.) as this one.
+func (t *Trie) emptyCopy() *Trie {
+	fresh := NewTrie()
+	if t.ascii {
+		fresh = NewTrieASCII()
+	}
+	fresh.fold = t.fold
+	fresh.normalize = t.normalize
+	if t.capacity > 0 {
+		fresh.setCapacity(t.capacity)
+	}
+	if t.arena != nil {
+		fresh.arena = &nodeArena{}
+	}
+	fresh.maxKeyRunes = t.maxKeyRunes
+	return fresh
+}
+
+// Returns ErrNotASCII if this is an ASCII trie and s isn't ASCII.
+func (t *Trie) checkASCII(s string) error {
+	if t.ascii {
+		for i := 0; i < len(s); i++ {
+			if s[i] >= utf8.RuneSelf {
+				return ErrNotASCII
+			}
+		}
+	}
+	return nil
+}
+
+// Converts s into the form it's stored in. Every exported method that
+// takes a string runs it through here first; everything below that
+// assumes it's been done.
+//
+// Strings with invalid utf8 in them are returned untouched, so the
+// error still gets noticed later.
+func (t *Trie) canonical(s string) string {
+	return canonicalize(s, t.fold, t.normalize)
+}
+
+// Does the work of canonical, for anything that keeps the same options
+// as a Trie.
+func canonicalize(s string, fold, normalize bool) string {
+	if !(fold || normalize) || !utf8.ValidString(s) {
+		return s
+	}
+	if fold {
+		s = strings.Map(unicode.ToLower, s)
 	}
+	if normalize {
+		s = norm.NFC.String(s)
+	}
+	return s
+}
 
+// Returns the trieNode of the last char in the given string. The empty
+// string maps to the root. If not found (or utf8 decode error), nil is
+// returned.
+func (t *Trie) searchNode(s string) *trieNode {
+	node, _ := t.searchNodeErr(s)
+	return node
+}
+
+// Same as searchNode, but reports ErrInvalidUTF8 if s isn't valid utf8,
+// even if the search fell off the trie before reaching the bad bytes.
+func (t *Trie) searchNodeErr(s string) (*trieNode, error) {
 	current := &t.root
-	for len(s) != 0 && current != nil {
+	for len(s) != 0 {
 		r, size := utf8.DecodeRuneInString(s)
-		if r == utf8.RuneError {
-			// TODO: Maybe return error instead?
-			return nil
+		if r == utf8.RuneError && size == 1 {
+			return nil, ErrInvalidUTF8
 		}
-		var ok bool
-		current, ok = current.children[r]
-		if !ok {
-			return nil
+		current = current.child(r)
+		if current == nil {
+			if !utf8.ValidString(s[size:]) {
+				return nil, ErrInvalidUTF8
+			}
+			return nil, nil
 		}
 		s = s[size:]
 	}
-	return current
+	return current, nil
 }
 
-// Searches for the given string in the trie.
+// Searches for the given string in the trie. The empty string is only
+// found if it was Put.
 //
 // Returns true on found, false on not found (or error decoding string)
 func (t *Trie) Has(s string) bool {
+	s = t.canonical(s)
 	res := t.searchNode(s)
 	return res != nil && res.isEnd
 }
 
-// Searches for the given string in the trie. This will return true if
-// there is either a full string or just the prefix of a string that
-// matches the input.
+// Returns true if any of ss is in the trie, stopping at the first one
+// that is. Strings with invalid utf8 in them are never found, as with
+// Has. An empty ss has nothing in the trie, so gives false.
+func (t *Trie) HasAny(ss []string) bool {
+	for _, s := range ss {
+		if t.Has(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if every one of ss is in the trie, stopping at the first
+// one that isn't. An empty ss gives true.
+func (t *Trie) HasAll(ss []string) bool {
+	for _, s := range ss {
+		if !t.Has(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Finds the longest string stored in the trie that is a prefix of s.
 //
-// Returns true on found, false on not found (or error decoding string).
-func (t *Trie) HasPrefix(s string) bool {
-	return t.searchNode(s) != nil
+// Returns the prefix (in the form it's stored in, see NewTrieFold) and
+// true on found. Returns "" and false if no
+// stored string is a prefix of s. Decoding stops at the first invalid
+// utf8 sequence, so only the valid leading part of s is considered.
+func (t *Trie) LongestPrefixOf(s string) (string, bool) {
+	s = t.canonical(s)
+	current := &t.root
+	longest, found := 0, current.isEnd
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+		current = current.child(r)
+		if current == nil {
+			break
+		}
+		i += size
+		if current.isEnd {
+			longest, found = i, true
+		}
+	}
+	return s[:longest], found
 }
 
-func (t *Trie) Delete(s string) {
-	if len(s) == 0 {
+// Calls fn with every string in the trie that's a prefix of s (including
+// s itself), shortest first, stopping if fn returns false. If the empty
+// string is in the trie, it counts. Handy for routing, where "/a" and
+// "/a/b" should both match "/a/b/c".
+//
+// Stops at the first bad byte if s has invalid utf8 in it.
+func (t *Trie) EachPrefixOf(s string, fn func(prefix string) bool) {
+	s = t.canonical(s)
+	if t.root.isEnd && !fn("") {
 		return
 	}
+	t.eachPrefixEnd(s, func(end int) bool {
+		return fn(s[:end])
+	})
+}
+
+// Returns every string in the trie that's a prefix of s (including s
+// itself), shortest first; see EachPrefixOf.
+//
+// Never returns nil; if nothing in the trie is a prefix of s, an empty
+// slice is returned.
+func (t *Trie) AllPrefixesOf(s string) []string {
+	prefixes := []string{}
+	t.EachPrefixOf(s, func(prefix string) bool {
+		prefixes = append(prefixes, prefix)
+		return true
+	})
+	return prefixes
+}
 
-	// The tradeoff here is to either give each trieNode
-	// a parent pointer and use searchNode, or to just memoize
-	// the last parent that was marked as !isEnd. More code duplication,
-	// but I'd rather that than use extra storage for each node.
+// Calls fn with the end (a byte offset) of every non-empty prefix of s
+// that's in the trie, shortest first. s must already be canonical. Stops
+// at invalid utf8.
+//
+// Returns false if fn asked to stop early.
+func (t *Trie) eachPrefixEnd(s string, fn func(end int) bool) bool {
 	current := &t.root
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+		current = current.child(r)
+		if current == nil {
+			break
+		}
+		i += size
+		if current.isEnd && !fn(i) {
+			return false
+		}
+	}
+	return true
+}
 
-	var lastNeededRune rune
-	var lastNeededNode *trieNode
+// Returns the longest prefix shared by every string in the trie. Stops
+// at the first string it reaches, so with "ab" and "abc" in the trie the
+// result is "ab". Returns "" for an empty trie.
+func (t *Trie) LongestCommonPrefix() string {
+	var prefix []rune
+	current := &t.root
+	for !current.isEnd && current.numChildren() == 1 {
+		for r, child := range current.children() {
+			prefix = append(prefix, r)
+			current = child
+		}
+	}
+	return string(prefix)
+}
+
+// Searches for the given string in the trie, like Has, but tells a
+// malformed query apart from a missing one.
+//
+// Returns false and ErrInvalidUTF8 if s isn't valid utf8; otherwise
+// returns whether s was found and a nil error.
+func (t *Trie) HasErr(s string) (bool, error) {
+	s = t.canonical(s)
+	res, err := t.searchNodeErr(s)
+	if err != nil {
+		return false, err
+	}
+	return res != nil && res.isEnd, nil
+}
+
+// Searches for the given string in the trie. This will return true if
+// there is either a full string or just the prefix of a string that
+// matches the input. The empty string is a prefix of everything, so
+// HasPrefix("") is true unless the trie is empty.
+//
+// Returns true on found, false on not found (or error decoding string).
+func (t *Trie) HasPrefix(s string) bool {
+	s = t.canonical(s)
+	node := t.searchNode(s)
+	return node != nil && (node.isEnd || node.numChildren() != 0)
+}
+
+// Returns how many leading runes of s are a path in the trie, i.e. the
+// length of the longest prefix of s that's also a prefix of some stored
+// string. Stops at the first invalid utf8 sequence in s.
+//
+// For tries made with NewTrieNormalized, runes are counted after s is
+// normalized, which may not match s rune for rune.
+func (t *Trie) CommonPrefixLen(s string) int {
+	s = t.canonical(s)
+	current, n := &t.root, 0
 	for len(s) != 0 {
-		var size int
 		r, size := utf8.DecodeRuneInString(s)
-		if r == utf8.RuneError {
-			// TODO: Maybe report error
-			return
+		if r == utf8.RuneError && size == 1 {
+			break
 		}
-
-		// 'Needed' is either the end of a word, or a node that
-		// has to support more than 1 child.
-		if current.isEnd || len(current.children) > 1 {
-			lastNeededRune = r
-			lastNeededNode = current
+		if current = current.child(r); current == nil {
+			break
 		}
+		n++
+		s = s[size:]
+	}
+	return n
+}
 
-		var ok bool
-		current, ok = current.children[r]
-		if !ok {
+// Cuts node out of the trie, along with every ancestor that's left
+// holding nothing: not the end of a string, and no other children. The
+// root is never removed.
+func (t *Trie) prune(node *trieNode) {
+	for node != &t.root {
+		parent := node.parent
+		parent.removeChild(node.value)
+		if parent.isEnd || parent.numChildren() != 0 {
 			return
 		}
+		node = parent
+	}
+}
 
-		s = s[size:]
+// Removes the given string from the trie, along with any nodes that
+// only existed to hold it. Prefixes of other strings stay put.
+//
+// The empty string is a valid key; deleting it only unmarks the root.
+func (t *Trie) Delete(s string) {
+	t.DeleteOk(s)
+}
+
+// Same as Delete, but reports whether s was in the trie (and so got
+// removed). Returns false if s wasn't there, including when it's only a
+// prefix of strings that are.
+func (t *Trie) DeleteOk(s string) bool {
+	s = t.canonical(s)
+	current := t.searchNode(s)
+	if current == nil || !current.isEnd {
+		// Missing, or only a prefix of something else; nothing to
+		// delete.
+		return false
 	}
+	t.unmarkEnd(current)
+	return true
+}
 
-	if len(current.children) != 0 {
-		current.isEnd = false
-	} else if lastNeededNode == nil {
-		// Even root wasn't needed? Sweet. Because this is a special
-		// case, it's handled (admittedly) somewhat stupidly.
-		if len(t.root.children) != 1 {
-			panic("Internal error: t.root.children has length != 1")
-		}
-		for k, _ := range t.root.children {
-			delete(t.root.children, k)
+// Deletes every one of ss from the trie, same as calling Delete on each.
+// Strings that aren't in the trie (including ones with invalid utf8 in
+// them) are skipped.
+//
+// Returns how many strings were actually removed; a string that shows up
+// in ss more than once is only counted once.
+func (t *Trie) DeleteAll(ss []string) int {
+	removed := 0
+	for _, s := range ss {
+		if t.DeleteOk(s) {
+			removed++
 		}
-	} else {
-		// Nothing depends on current. Delete every node that
-		// only current depends on.
-		delete(lastNeededNode.children, lastNeededRune)
 	}
+	return removed
 }
 
-// Adds a child node and returns the trieNode that 'represents' it.
-func (t *trieNode) addChildNode(r rune) *trieNode {
-	node, ok := t.children[r]
-	if !ok {
-		node = newTrieNode(r)
-		t.children[r] = node
+// Undoes markEnd: node is no longer the end of a string, and is cut out
+// of the trie if nothing else depends on it.
+func (t *Trie) unmarkEnd(node *trieNode) {
+	var word string
+	if len(t.observers) != 0 {
+		word = node.word()
 	}
-	return node
+	t.forget(node)
+	t.size--
+	node.isEnd = false
+	node.count = 0
+	for n := node; n != nil; n = n.parent {
+		n.ends--
+	}
+	if node.numChildren() == 0 {
+		t.prune(node)
+	}
+	if len(t.observers) != 0 {
+		t.notify(Delete, word)
+	}
+}
+
+// Removes every string that starts with prefix (including prefix
+// itself) from the trie, cleaning up nodes the same way Delete does.
+// DeletePrefix("") empties the trie.
+//
+// Returns the number of strings removed, which is 0 if nothing starts
+// with prefix (or prefix has invalid utf8 in it).
+func (t *Trie) DeletePrefix(prefix string) int {
+	prefix = t.canonical(prefix)
+	current := t.searchNode(prefix)
+	if current == nil {
+		return 0
+	}
+	removed := current.ends
+	t.removeSubtree(current)
+	return removed
+}
+
+// Same as DeletePrefix, but returns the strings that were removed, in
+// the same order as Keys.
+//
+// Never returns nil; if nothing starts with prefix, an empty slice is
+// returned and nothing changes.
+func (t *Trie) DeletePrefixKeys(prefix string) []string {
+	prefix = t.canonical(prefix)
+	current := t.searchNode(prefix)
+	if current == nil {
+		return []string{}
+	}
+	removed := current.collect([]rune(prefix), []string{})
+	t.removeSubtree(current)
+	return removed
+}
+
+// The opposite of DeletePrefix: removes every string that doesn't start
+// with prefix, leaving just the strings under it. Strings that are
+// themselves proper prefixes of prefix are removed too. If nothing starts
+// with prefix (or prefix has invalid utf8 in it), the trie is emptied.
+//
+// Returns the number of strings removed.
+func (t *Trie) RetainPrefix(prefix string) int {
+	prefix = t.canonical(prefix)
+	runes, path := t.followPath(prefix)
+	if !utf8.ValidString(prefix) || len(path) != len(runes)+1 {
+		removed := t.size
+		t.Clear()
+		return removed
+	}
+
+	kept := path[len(path)-1]
+	removed := t.size - kept.ends
+	var words []string
+	for depth, node := range path[:len(path)-1] {
+		if node.isEnd {
+			t.forget(node)
+			node.isEnd = false
+			node.count = 0
+			if len(t.observers) != 0 {
+				words = append(words, string(runes[:depth]))
+			}
+		}
+		for _, r := range node.sortedRunes() {
+			if r == runes[depth] {
+				continue
+			}
+			child := node.child(r)
+			if t.capacity > 0 {
+				child.walkEnds(nil, func(_ []rune, end *trieNode) bool {
+					t.forget(end)
+					return true
+				})
+			}
+			if len(t.observers) != 0 {
+				words = child.collect(append(slices.Clip(runes[:depth]), r), words)
+			}
+			node.removeChild(r)
+		}
+		node.ends = kept.ends
+	}
+	t.size = kept.ends
+	slices.Sort(words)
+	t.notifyAll(Delete, words)
+	return removed
 }
 
-// Implementation of `Put`, but assuming everything in the string
-// is valid.
-func (t *trieNode) putValid(s string) *trieNode {
-	if len(s) == 0 {
-		t.isEnd = true
-		return t
+// Removes node and every string that ends at or below it, pruning the
+// same way Delete does.
+func (t *Trie) removeSubtree(node *trieNode) {
+	if node == &t.root {
+		t.Clear()
+		return
+	}
+	removed := t.wordsToNotify(node)
+	t.size -= node.ends
+	for n := node.parent; n != nil; n = n.parent {
+		n.ends -= node.ends
 	}
+	if t.capacity > 0 {
+		node.walkEnds(nil, func(_ []rune, end *trieNode) bool {
+			t.forget(end)
+			return true
+		})
+	}
+	t.prune(node)
+	t.notifyAll(Delete, removed)
+}
 
-	r, size := utf8.DecodeRuneInString(s)
-	next := t.addChildNode(r)
-	return next.putValid(s[size:])
+// Returns parent's child for r, adding one if it isn't there yet. In an
+// ASCII trie, r must be ASCII.
+func (t *Trie) addChild(parent *trieNode, r rune) *trieNode {
+	node := parent.child(r)
+	if node == nil {
+		node = t.arena.newNode(r)
+		node.parent = parent
+		if t.ascii && parent.ascii == nil {
+			parent.ascii = &asciiChildren{}
+		}
+		parent.setChild(r, node)
+	}
+	return node
 }
 
 // Puts a full string of runes into the given Trie.
 //
 // Returns the terminating trieNode and a nil error on success,
-// returns nil and an error on failure. Currently, failure only
-// happens if s has an invalid utf-8 sequence in it.
+// returns nil and an error on failure. Failure happens if s has an
+// invalid utf-8 sequence in it, in which case the error is
+// ErrInvalidUTF8, if this trie was made by NewTrieASCII and s isn't
+// ASCII, in which case the error is ErrNotASCII, or if this trie was made
+// by NewTrieMaxKeyRunes and s is too long, in which case the error is
+// ErrKeyTooLong. Nothing is stored on failure.
 func (t *Trie) Put(s string) error {
-	if !utf8.ValidString(s) {
-		return errors.New("Invalid utf8 in string")
+	_, err := t.PutNew(s)
+	return err
+}
+
+// Same as Put, but also reports whether s was newly added (true) or was
+// already in the trie (false). added is always false on error.
+func (t *Trie) PutNew(s string) (added bool, err error) {
+	_, added, err = t.insert(s)
+	return added, err
+}
+
+// Same as Put, but rather than just rejecting s when it goes bad part
+// way through, says how much of it was good: how many runes come before
+// the first invalid utf8 sequence (or, in an ASCII trie, the first
+// non-ASCII rune), counted in the form they'd be stored in (see
+// NewTrieFold). s is only stored, and only marked as the end of a
+// string, if all of it was good. Otherwise the trie is left as it was:
+// the good part was never put, so Has won't report it, and a branch that
+// no string ends under can't be kept in the trie.
+//
+// err is nil if all of s was stored; otherwise it says where s was cut
+// off, and wraps ErrInvalidUTF8 or ErrNotASCII. Other errors from Put
+// (e.g. ErrKeyTooLong) are returned as they are, with runesStored 0.
+func (t *Trie) PutLenient(s string) (runesStored int, err error) {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			err = ErrInvalidUTF8
+		} else if t.ascii && r >= utf8.RuneSelf {
+			err = ErrNotASCII
+		}
+		if err != nil {
+			good := utf8.RuneCountInString(t.canonical(s[:i]))
+			return good, fmt.Errorf("cut off at byte %d: %w", i, err)
+		}
+		i += size
 	}
 
-	// TODO: It might be worthwhile to make undos possible, so we can
-	// not walk the string twice for this.
+	if _, _, err := t.insert(s); err != nil {
+		return 0, err
+	}
+	return utf8.RuneCountInString(t.canonical(s)), nil
+}
 
-	node := &t.root
-	for len(s) != 0 {
-		r, size := utf8.DecodeRuneInString(s)
-		node = node.addChildNode(r)
-		s = s[size:]
+// Same as Put, but calls onDup (if it's not nil) when s was already in
+// the trie. Errors are the same as Put's, and onDup isn't called on
+// error.
+func (t *Trie) PutFunc(s string, onDup func()) error {
+	added, err := t.PutNew(s)
+	if err == nil && !added && onDup != nil {
+		onDup()
+	}
+	return err
+}
+
+// Does the work of PutNew, also returning the node that s ends at (nil
+// on error).
+func (t *Trie) insert(s string) (node *trieNode, added bool, err error) {
+	s = t.canonical(s)
+
+	// s is checked as it's decoded, rather than up front. If it turns
+	// out to be bad, the branch made for it so far gets cut off again,
+	// which leaves the trie as it was.
+	var grownFrom *trieNode
+	var grownRune rune
+	node = &t.root
+	// s has used up its runes (see NewTrieMaxKeyRunes) once i gets to
+	// bound: the limit, plus however many extra bytes the multibyte runes
+	// so far took. With no limit, i never gets there.
+	bound := len(s)
+	if t.maxKeyRunes > 0 {
+		bound = t.maxKeyRunes
+	}
+	for i := 0; i < len(s); {
+		if i == bound {
+			return nil, false, undoGrowth(grownFrom, grownRune, t.tooLongError(s[i:]))
+		}
+		r, size := rune(s[i]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRuneInString(s[i:])
+			if t.ascii || (r == utf8.RuneError && size == 1) {
+				return nil, false, undoGrowth(grownFrom, grownRune, t.putError(s[i:]))
+			}
+			bound += size - 1
+		}
+
+		next := node.child(r)
+		if next == nil {
+			if grownFrom == nil {
+				grownFrom, grownRune = node, r
+			}
+			next = t.addChild(node, r)
+		}
+		node = next
+		i += size
+	}
+	added = t.markEnd(node)
+	t.evict()
+	if added && len(t.observers) != 0 {
+		t.notify(Insert, s)
+	}
+	return node, added, nil
+}
+
+// Cuts off the branch insert grew for a string that turned out to be bad,
+// and returns err.
+func undoGrowth(grownFrom *trieNode, grownRune rune, err error) error {
+	if grownFrom != nil {
+		grownFrom.removeChild(grownRune)
+	}
+	return err
+}
+
+// Returns the error Put gives for a string that goes bad at rest: the
+// first rune of rest is either invalid, or isn't ASCII in an ASCII trie.
+// Bad utf8 anywhere takes priority, same as if the whole string had been
+// checked up front.
+func (t *Trie) putError(rest string) error {
+	if !utf8.ValidString(rest) {
+		return ErrInvalidUTF8
+	}
+	return ErrNotASCII
+}
+
+// Returns the error Put gives for a string that has already used up its
+// runes by the time it gets to rest. Either of putError's errors take
+// priority, same as if the whole string had been checked up front.
+func (t *Trie) tooLongError(rest string) error {
+	if !utf8.ValidString(rest) {
+		return ErrInvalidUTF8
+	}
+	if err := t.checkASCII(rest); err != nil {
+		return err
+	}
+	return ErrKeyTooLong
+}
+
+// Marks node as the end of a string, keeping count if it wasn't already.
+// For capped tries, node becomes the most recently put string either
+// way, but nothing is evicted; that's up to the caller.
+//
+// Returns true if node wasn't already the end of a string.
+func (t *Trie) markEnd(node *trieNode) bool {
+	t.touch(node)
+	if node.isEnd {
+		return false
 	}
 	node.isEnd = true
+	node.count = 1
+	t.size++
+	for ; node != nil; node = node.parent {
+		node.ends++
+	}
+	return true
+}
 
-	return nil
+// Returns the number of strings stored in the trie.
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// Reports whether the trie holds no strings, not even the empty string.
+func (t *Trie) IsEmpty() bool {
+	return !t.root.isEnd && t.root.numChildren() == 0
+}
+
+// Removes every string from the trie, leaving it ready to be reused.
+func (t *Trie) Clear() {
+	removed := t.wordsToNotify(&t.root)
+	t.root.clearChildren()
+	t.root.isEnd = false
+	t.root.count = 0
+	t.root.ends = 0
+	t.size = 0
+	if t.capacity > 0 {
+		t.setCapacity(t.capacity)
+	}
+	if t.arena != nil {
+		t.arena = &nodeArena{}
+	}
+	t.notifyAll(Delete, removed)
+}
+
+// Returns a deep copy of this node and everything below it.
+func (t *trieNode) clone() *trieNode {
+	node := &trieNode{
+		value: t.value,
+		isEnd: t.isEnd,
+		count: t.count,
+		ends:  t.ends,
+	}
+	if t.ascii != nil {
+		node.ascii = &asciiChildren{n: t.ascii.n}
+		for r, child := range t.ascii.nodes {
+			if child != nil {
+				node.ascii.nodes[r] = child.clone()
+			}
+		}
+	} else if t.big != nil {
+		node.big = make(map[rune]*trieNode, len(t.big))
+		for r, child := range t.big {
+			node.big[r] = child.clone()
+		}
+	} else if t.small != nil {
+		node.small = make([]trieEdge, len(t.small))
+		for i, e := range t.small {
+			node.small[i] = trieEdge{e.r, e.node.clone()}
+		}
+	}
+	node.adoptChildren()
+	return node
+}
+
+// Points the parent of each of this node's children at this node. Needed
+// whenever a node gets copied, like a Trie's root.
+func (t *trieNode) adoptChildren() {
+	for _, child := range t.children() {
+		child.parent = t
+	}
+}
+
+// Returns a copy of the trie that shares no nodes with the original, so
+// either one can be modified without affecting the other.
+//
+// Never returns nil.
+func (t *Trie) Clone() *Trie {
+	clone := t.emptyCopy()
+	clone.root = *t.root.clone()
+	clone.root.adoptChildren()
+	clone.size = t.size
+	if t.capacity > 0 {
+		for e := t.order.Front(); e != nil; e = e.Next() {
+			word := e.Value.(*trieNode).word()
+			clone.touch(clone.searchNode(word))
+		}
+	}
+	return clone
+}
+
+// Returns a copy of everything in the trie that starts with prefix, with
+// prefix stripped off each string. So if the trie holds "com.example.a"
+// and "com.example.b", SubTrie("com.example.") holds "a" and "b". The
+// copy shares no nodes with the original, and has the same options.
+//
+// Returns nil and false if nothing in the trie starts with prefix.
+func (t *Trie) SubTrie(prefix string) (*Trie, bool) {
+	prefix = t.canonical(prefix)
+	node := t.searchNode(prefix)
+	if node == nil {
+		return nil, false
+	}
+
+	sub := t.emptyCopy()
+	sub.root = *node.clone()
+	sub.root.value = 0
+	sub.root.parent = nil
+	sub.root.adoptChildren()
+	sub.size = sub.root.ends
+	sub.rebuildOrder()
+	return sub, true
+}
+
+// Splits the trie in two at pivot: lo gets every string less than pivot,
+// and hi gets every other string (pivot included), in the same order as
+// Keys. Both are new tries with the same options as this one, sharing no
+// nodes with it or each other; the trie itself isn't changed. Any bytes
+// in pivot that aren't valid utf8 are compared as U+FFFD, as in Rank.
+//
+// Rather than putting every string into lo or hi one at a time, whole
+// branches that sit on one side of pivot are copied over as they are.
+// Only the nodes on the path to pivot get split.
+//
+// Never returns nil tries, though one or both may be empty.
+func (t *Trie) SplitAt(pivot string) (lo, hi *Trie) {
+	loRoot, hiRoot := t.root.splitAt([]rune(t.canonical(pivot)))
+	return t.withRoot(loRoot), t.withRoot(hiRoot)
+}
+
+// Returns an empty copy of the trie (see emptyCopy) that's been given
+// root, if it's not nil.
+func (t *Trie) withRoot(root *trieNode) *Trie {
+	out := t.emptyCopy()
+	if root != nil {
+		out.root = *root
+		out.root.adoptChildren()
+		out.size = out.root.ends
+		out.rebuildOrder()
+	}
+	return out
+}
+
+// Copies the strings at or below this node that are less than its string
+// plus rest into lo, and the others into hi. Either is nil if it would
+// be empty.
+func (t *trieNode) splitAt(rest []rune) (lo, hi *trieNode) {
+	if len(rest) == 0 {
+		// Everything here is at least pivot.
+		return nil, t.clone()
+	}
+
+	// This node's own string is a proper prefix of pivot, so it's less.
+	lo = &trieNode{value: t.value, isEnd: t.isEnd, count: t.count}
+	hi = &trieNode{value: t.value}
+	for r, child := range t.sortedChildren() {
+		var loChild, hiChild *trieNode
+		switch {
+		case r < rest[0]:
+			loChild = child.clone()
+		case r > rest[0]:
+			hiChild = child.clone()
+		default:
+			loChild, hiChild = child.splitAt(rest[1:])
+		}
+		if loChild != nil {
+			lo.adoptChild(r, loChild, t.ascii != nil)
+		}
+		if hiChild != nil {
+			hi.adoptChild(r, hiChild, t.ascii != nil)
+		}
+	}
+	if lo.isEnd {
+		lo.ends++
+	}
+
+	if lo.ends == 0 {
+		lo = nil
+	}
+	if hi.ends == 0 {
+		hi = nil
+	}
+	return lo, hi
+}
+
+// Hangs child, a node that's not in any trie yet, off of this node as the
+// child for r, counting its strings as this node's. If ascii is set, the
+// child goes in an ASCII array, as in a trie made by NewTrieASCII.
+func (t *trieNode) adoptChild(r rune, child *trieNode, ascii bool) {
+	child.parent = t
+	if ascii && t.ascii == nil {
+		t.ascii = &asciiChildren{}
+	}
+	t.setChild(r, child)
+	t.ends += child.ends
+}
+
+// Returns the runes of this node's children in ascending order.
+func (t *trieNode) sortedRunes() []rune {
+	runes := make([]rune, 0, t.numChildren())
+	for r := range t.children() {
+		runes = append(runes, r)
+	}
+	if t.big != nil {
+		slices.Sort(runes)
+	}
+	return runes
+}
+
+// Calls fn on every string that ends at or below this node, in
+// lexicographic order. prefix holds the runes on the path from the root
+// down to this node.
+//
+// Returns false if fn asked to stop early.
+func (t *trieNode) walk(prefix []rune, fn func(string) bool) bool {
+	return t.walkEnds(prefix, func(word []rune, _ *trieNode) bool {
+		return fn(string(word))
+	})
+}
+
+// Same as walk, but hands fn the node each string ends at, with the
+// string still as runes. fn must not hold on to word; it gets reused.
+func (t *trieNode) walkEnds(prefix []rune, fn func(word []rune, node *trieNode) bool) bool {
+	if t.isEnd && !fn(prefix, t) {
+		return false
+	}
+	for r, child := range t.sortedChildren() {
+		if !child.walkEnds(append(prefix, r), fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Same as walk, but in reverse (descending) lexicographic order: children
+// in descending order first, then this node's own string.
+func (t *trieNode) walkReverse(prefix []rune, fn func(string) bool) bool {
+	runes := t.sortedRunes()
+	for i := len(runes) - 1; i >= 0; i-- {
+		r := runes[i]
+		if !t.child(r).walkReverse(append(prefix, r), fn) {
+			return false
+		}
+	}
+	return !t.isEnd || fn(string(prefix))
+}
+
+// Appends every string that ends at or below this node to out. prefix
+// holds the runes on the path from the root down to this node.
+func (t *trieNode) collect(prefix []rune, out []string) []string {
+	t.walk(prefix, func(s string) bool {
+		out = append(out, s)
+		return true
+	})
+	return out
+}
+
+// Calls fn on every string stored in the trie. Strings are visited in
+// lexicographic order by rune value, which is a depth-first, preorder
+// walk with children taken in ascending order. (For valid utf8, that's
+// also the order sort.Strings puts them in.) This order is guaranteed,
+// and every other method that lists strings out of the trie (Keys, All,
+// KeysWithPrefix, ...) uses it, so they can be used to stream a sorted
+// dictionary without sorting anything.
+//
+// If fn returns false, the walk stops immediately; no further strings
+// (siblings included) are visited.
+func (t *Trie) Walk(fn func(word string) bool) {
+	t.root.walk(nil, fn)
+}
+
+// Returns an iterator over every string stored in the trie, in the same
+// (sorted) order as Walk. Nothing is collected up front, so this is the
+// way to stream every key out in order without holding them all.
+func (t *Trie) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		t.root.walk(nil, yield)
+	}
+}
+
+// Returns an iterator over every string stored in the trie that starts
+// with prefix, in the same order as Walk. Yields nothing if no string
+// starts with prefix.
+func (t *Trie) WithPrefix(prefix string) iter.Seq[string] {
+	prefix = t.canonical(prefix)
+	return func(yield func(string) bool) {
+		if node := t.searchNode(prefix); node != nil {
+			node.walk([]rune(prefix), yield)
+		}
+	}
+}
+
+// Returns every string stored in the trie, ordered lexicographically
+// by rune value.
+//
+// Never returns nil; an empty trie gives an empty slice.
+func (t *Trie) Keys() []string {
+	return t.root.collect(nil, []string{})
+}
+
+// Returns a new map with every string in the trie as a key, each mapped
+// to true, for code that wants a map[string]bool.
+//
+// Never returns nil; an empty trie gives an empty map.
+func (t *Trie) ToMap() map[string]bool {
+	out := make(map[string]bool, t.size)
+	t.root.walk(nil, func(s string) bool {
+		out[s] = true
+		return true
+	})
+	return out
+}
+
+// Returns every string stored in the trie in descending lexicographic
+// order by rune value; exactly the reverse of Keys.
+//
+// Never returns nil; an empty trie gives an empty slice.
+func (t *Trie) ReverseKeys() []string {
+	out := []string{}
+	t.root.walkReverse(nil, func(s string) bool {
+		out = append(out, s)
+		return true
+	})
+	return out
+}
+
+// Returns the smallest string in the trie, in the same order as Keys.
+// Only the path down to it is visited.
+//
+// Returns false if the trie is empty.
+func (t *Trie) Min() (string, bool) {
+	return t.root.first(nil)
+}
+
+// Returns the largest string in the trie, in the same order as Keys. A
+// string is never the largest if something longer starts with it, so
+// with "ab" and "abc" in the trie, Max is "abc". Only the path down to it
+// is visited.
+//
+// Returns false if the trie is empty.
+func (t *Trie) Max() (string, bool) {
+	return t.root.last(nil)
+}
+
+// Returns the smallest string that ends at or below this node. prefix
+// holds the runes on the path from the root down to this node.
+func (t *trieNode) first(prefix []rune) (string, bool) {
+	smallest, found := "", false
+	t.walk(prefix, func(s string) bool {
+		smallest, found = s, true
+		return false
+	})
+	return smallest, found
+}
+
+// Returns the largest string that ends at or below this node. prefix
+// holds the runes on the path from the root down to this node.
+func (t *trieNode) last(prefix []rune) (string, bool) {
+	largest, found := "", false
+	t.walkReverse(prefix, func(s string) bool {
+		largest, found = s, true
+		return false
+	})
+	return largest, found
+}
+
+// Follows s as far down the trie as it goes. Returns s as runes, and the
+// nodes on the way; path[i] is the node for s[:i], and path[0] is the
+// root. If s is in the trie (as a string or a prefix), path has one more
+// node than s has runes.
+func (t *Trie) followPath(s string) (runes []rune, path []*trieNode) {
+	runes = []rune(s)
+	path = []*trieNode{&t.root}
+	for _, r := range runes {
+		next := path[len(path)-1].child(r)
+		if next == nil {
+			break
+		}
+		path = append(path, next)
+	}
+	return runes, path
+}
+
+// Returns the largest string in the trie that's less than or equal to s,
+// in the same order as Keys.
+//
+// Returns false if every string in the trie is greater than s, or if s
+// isn't valid utf8.
+func (t *Trie) Floor(s string) (string, bool) {
+	return t.floor(s, true)
+}
+
+// Returns the largest string in the trie that's less than s, in the same
+// order as Keys. Unlike Floor, s itself is never returned, so this steps
+// backwards through the trie one string at a time.
+//
+// Returns false if every string in the trie is at least s (e.g. s is
+// Min), or if s isn't valid utf8.
+func (t *Trie) Predecessor(s string) (string, bool) {
+	return t.floor(s, false)
+}
+
+// Same as Floor if inclusive is set, and Predecessor otherwise.
+func (t *Trie) floor(s string, inclusive bool) (string, bool) {
+	if !utf8.ValidString(s) {
+		return "", false
+	}
+	runes, path := t.followPath(t.canonical(s))
+	depth := len(path) - 1
+	if depth == len(runes) {
+		if inclusive && path[depth].isEnd {
+			return string(runes), true
+		}
+		// Everything below s is greater than it.
+		depth--
+	}
+
+	// Nothing below path[depth+1] is small enough, so back up until
+	// there's a smaller branch, or a string that's a prefix of s.
+	for ; depth >= 0; depth-- {
+		node := path[depth]
+		siblings := node.sortedRunes()
+		i, _ := slices.BinarySearch(siblings, runes[depth])
+		if i > 0 {
+			r := siblings[i-1]
+			prefix := append(slices.Clip(runes[:depth]), r)
+			return node.child(r).last(prefix)
+		}
+		if node.isEnd {
+			return string(runes[:depth]), true
+		}
+	}
+	return "", false
+}
+
+// Returns the smallest string in the trie that's greater than or equal to
+// s, in the same order as Keys.
+//
+// Returns false if every string in the trie is less than s, or if s isn't
+// valid utf8.
+func (t *Trie) Ceiling(s string) (string, bool) {
+	return t.ceiling(s, true)
+}
+
+// Returns the smallest string in the trie that's greater than s, in the
+// same order as Keys. Unlike Ceiling, s itself is never returned, so
+// paging through the trie can pick up from Successor(lastKey).
+//
+// Returns false if every string in the trie is at most s (e.g. s is Max),
+// or if s isn't valid utf8.
+func (t *Trie) Successor(s string) (string, bool) {
+	return t.ceiling(s, false)
+}
+
+// Same as Ceiling if inclusive is set, and Successor otherwise.
+func (t *Trie) ceiling(s string, inclusive bool) (string, bool) {
+	if !utf8.ValidString(s) {
+		return "", false
+	}
+	runes, path := t.followPath(t.canonical(s))
+	depth := len(path) - 1
+	if depth == len(runes) {
+		// s and everything below it are at least s, and s is smallest.
+		if inclusive {
+			return path[depth].first(runes)
+		}
+		// Past s itself, the smallest is under its smallest child. With
+		// no children, back up like below.
+		for r, child := range path[depth].sortedChildren() {
+			return child.first(append(slices.Clip(runes), r))
+		}
+		depth--
+	}
+
+	// Nothing below path[depth+1] exists, so back up until there's a
+	// bigger branch. Strings on the way up are prefixes of s, and so are
+	// smaller than it.
+	for ; depth >= 0; depth-- {
+		node := path[depth]
+		siblings := node.sortedRunes()
+		i, found := slices.BinarySearch(siblings, runes[depth])
+		if found {
+			i++
+		}
+		if i < len(siblings) {
+			r := siblings[i]
+			prefix := append(slices.Clip(runes[:depth]), r)
+			return node.child(r).first(prefix)
+		}
+	}
+	return "", false
+}
+
+// Returns every string w in the trie with lo <= w < hi, in the same order
+// as Keys. The range is half-open: lo is included if it's in the trie,
+// but hi never is. Only the parts of the trie that lie between lo and hi
+// are visited.
+//
+// Never returns nil; an empty range (including lo >= hi, or either one
+// not being valid utf8) gives an empty slice.
+func (t *Trie) RangeKeys(lo, hi string) []string {
+	out := []string{}
+	if !utf8.ValidString(lo) || !utf8.ValidString(hi) {
+		return out
+	}
+	lo, hi = t.canonical(lo), t.canonical(hi)
+	if lo >= hi {
+		return out
+	}
+	t.root.walkRange(nil, []rune(lo), []rune(hi), true, true, func(s string) {
+		out = append(out, s)
+	})
+	return out
+}
+
+// Calls fn, in order, on every string at or below this node that's at
+// least lo and less than hi. prefix holds the runes on the path from the
+// root down to this node. loTight is set if prefix is a prefix of lo, and
+// likewise for hiTight; otherwise, prefix is already known to be greater
+// than lo (or less than hi).
+//
+// Returns false once it reaches hi, since nothing after that is in range.
+func (t *trieNode) walkRange(prefix, lo, hi []rune, loTight, hiTight bool, fn func(string)) bool {
+	depth := len(prefix)
+	if hiTight && depth == len(hi) {
+		return false
+	}
+	if t.isEnd && (!loTight || depth == len(lo)) {
+		fn(string(prefix))
+	}
+	for r, child := range t.sortedChildren() {
+		childLoTight := false
+		if loTight && depth < len(lo) {
+			if r < lo[depth] {
+				continue
+			}
+			childLoTight = r == lo[depth]
+		}
+		childHiTight := false
+		if hiTight {
+			if r > hi[depth] {
+				return false
+			}
+			childHiTight = r == hi[depth]
+		}
+		if !child.walkRange(append(prefix, r), lo, hi, childLoTight, childHiTight, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the number of strings in the trie that are less than s, in the
+// same order as Keys; that is, the index s has or would have in Keys.
+// Any bytes in s that aren't valid utf8 are compared as U+FFFD.
+//
+// Only the path down to s, and the children along it, are visited.
+func (t *Trie) Rank(s string) int {
+	rank := 0
+	current := &t.root
+	for _, r := range t.canonical(s) {
+		// A string ending here is a prefix of s, so it comes first.
+		if current.isEnd {
+			rank++
+		}
+		for cr, child := range current.sortedChildren() {
+			if cr >= r {
+				break
+			}
+			rank += child.ends
+		}
+		current = current.child(r)
+		if current == nil {
+			break
+		}
+	}
+	return rank
+}
+
+// Returns the string at index i of Keys, without building Keys. Together
+// with Rank, this is enough to page through the trie in order. Only the
+// path down to the string, and the children along it, are visited.
+//
+// Returns false if i is out of range.
+func (t *Trie) Select(i int) (string, bool) {
+	if i < 0 || i >= t.size {
+		return "", false
+	}
+
+	var prefix []rune
+	current := &t.root
+	for {
+		if current.isEnd {
+			if i == 0 {
+				return string(prefix), true
+			}
+			i--
+		}
+		var next *trieNode
+		for r, child := range current.sortedChildren() {
+			n := child.ends
+			if i < n {
+				prefix = append(prefix, r)
+				next = child
+				break
+			}
+			i -= n
+		}
+		if next == nil {
+			// Only possible if size is wrong.
+			return "", false
+		}
+		current = next
+	}
+}
+
+// Returns the number of nodes in the subtree rooted at this node,
+// including this node.
+func (t *trieNode) countNodes() int {
+	n := 1
+	for _, child := range t.children() {
+		n += child.countNodes()
+	}
+	return n
+}
+
+// Returns the number of strings stored in the trie that start with
+// prefix, including prefix itself if it was stored. Returns 0 if nothing
+// starts with prefix (or prefix has invalid utf8 in it).
+//
+// Every node keeps count of the strings at or below it, so this only
+// visits the path down to prefix, however many strings start with it.
+func (t *Trie) CountWithPrefix(prefix string) int {
+	prefix = t.canonical(prefix)
+	node := t.searchNode(prefix)
+	if node == nil {
+		return 0
+	}
+	return node.ends
+}
+
+// Returns every string stored in the trie that starts with prefix,
+// including prefix itself if it was stored. Results are ordered
+// lexicographically by rune value.
+//
+// Never returns nil; if nothing starts with prefix (or prefix has
+// invalid utf8 in it), an empty slice is returned.
+func (t *Trie) KeysWithPrefix(prefix string) []string {
+	prefix = t.canonical(prefix)
+	node := t.searchNode(prefix)
+	if node == nil {
+		return []string{}
+	}
+	return node.collect([]rune(prefix), []string{})
+}
+
+// Groups every string in the trie by its first n runes, in one walk. The
+// result maps each distinct n-rune prefix to the strings that start with
+// it, in the same order as Keys. Strings shorter than n runes are grouped
+// under themselves, so "go" ends up with "go" whether or not "gopher" is
+// in the trie. If n <= 0, everything is grouped under "".
+//
+// Never returns nil.
+func (t *Trie) GroupByPrefix(n int) map[string][]string {
+	n = max(n, 0)
+	groups := make(map[string][]string)
+	t.root.walk(nil, func(s string) bool {
+		end := 0
+		for i := 0; i < n && end < len(s); i++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		groups[s[:end]] = append(groups[s[:end]], s)
+		return true
+	})
+	return groups
+}
+
+// Returns up to n of the strings stored in the trie that start with
+// prefix, in the same order as KeysWithPrefix. The walk stops as soon as
+// n have been found, so this takes about as long for a prefix with a
+// million completions as it does for one with n.
+//
+// Never returns nil; if n <= 0 or nothing starts with prefix, an empty
+// slice is returned.
+func (t *Trie) CompleteN(prefix string, n int) []string {
+	out := []string{}
+	if n <= 0 {
+		return out
+	}
+
+	prefix = t.canonical(prefix)
+	node := t.searchNode(prefix)
+	if node == nil {
+		return out
+	}
+	node.walk([]rune(prefix), func(s string) bool {
+		out = append(out, s)
+		return len(out) < n
+	})
+	return out
 }
@@ -0,0 +1,90 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTrieArena(t *testing.T) {
+	words := []string{"apple", "app", "banana", "band", "", "été"}
+	for round := 0; round < 3; round++ {
+		trie := NewTrieArena()
+		plain := NewTrie()
+		// Enough nodes to need a few slabs.
+		for i := 0; i < 3*arenaSlabSize; i++ {
+			w := words[i%len(words)] + string(rune('a'+i%26)) + string(rune('a'+i/26%26))
+			trie.Put(w)
+			plain.Put(w)
+		}
+		for _, w := range words {
+			trie.Put(w)
+			plain.Put(w)
+		}
+		if !trie.Equal(plain) || trie.Len() != plain.Len() || !slices.Equal(trie.Keys(), plain.Keys()) {
+			t.Fatal("Expected an arena trie to hold the same strings as a plain one")
+		}
+		checkParents(t, &trie.root)
+
+		trie.Delete("band")
+		trie.DeletePrefix("ap")
+		if trie.Has("band") || trie.HasPrefix("ap") || !trie.Has("banana") {
+			t.Fatal("Unexpected contents after deletes:", trie.Keys())
+		}
+
+		// Releasing and refilling reuses slabs, and has to leave no
+		// trace of what was there before.
+		slabs := len(trie.arena.slabs)
+		trie.Release()
+		if !trie.IsEmpty() || trie.Len() != 0 || len(trie.arena.spare) != slabs {
+			t.Fatal("Expected Release to empty the trie and keep its slabs")
+		}
+		trie.Put("fresh")
+		if !slices.Equal(trie.Keys(), []string{"fresh"}) || trie.NodeCount() != 5 {
+			t.Fatal("Expected a released trie to start clean; got", trie.Keys())
+		}
+	}
+
+	// Clear gives the memory up instead.
+	trie := NewTrieArena()
+	trie.Put("abc")
+	trie.Clear()
+	if len(trie.arena.slabs)+len(trie.arena.spare) != 0 {
+		t.Fatal("Expected Clear to drop the arena's slabs")
+	}
+
+	// Release on a plain trie is just Clear.
+	plain := newTrieOf("abc")
+	plain.Release()
+	if !plain.IsEmpty() || plain.arena != nil {
+		t.Fatal("Expected Release to clear a plain trie")
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+// Compare with BenchmarkLargeTrieBuild, particularly gcs/op.
+func BenchmarkLargeTrieBuildArena(b *testing.B) {
+	benchmarkLargeBuild(b, func(strings []string) int {
+		trie := NewTrieArena()
+		for _, s := range strings {
+			trie.Put(s)
+		}
+		return trie.root.countNodes()
+	})
+}
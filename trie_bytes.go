@@ -0,0 +1,94 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"unicode/utf8"
+)
+
+// Same as searchNode, but for a []byte.
+func (t *Trie) searchNodeBytes(b []byte) *trieNode {
+	current := &t.root
+	for len(b) != 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size == 1 {
+			return nil
+		}
+		current = current.child(r)
+		if current == nil {
+			return nil
+		}
+		b = b[size:]
+	}
+	return current
+}
+
+// Same as Put, but decodes runes straight out of b rather than making
+// the caller convert it to a string first.
+func (t *Trie) PutBytes(b []byte) error {
+	if t.fold || t.normalize {
+		// Needs a string to be canonicalized anyway.
+		return t.Put(string(b))
+	}
+	if !utf8.Valid(b) {
+		return ErrInvalidUTF8
+	}
+	if t.ascii {
+		for _, c := range b {
+			if c >= utf8.RuneSelf {
+				return ErrNotASCII
+			}
+		}
+	}
+	if t.maxKeyRunes > 0 && utf8.RuneCount(b) > t.maxKeyRunes {
+		return ErrKeyTooLong
+	}
+
+	node := &t.root
+	for rest := b; len(rest) != 0; {
+		r, size := utf8.DecodeRune(rest)
+		node = t.addChild(node, r)
+		rest = rest[size:]
+	}
+	added := t.markEnd(node)
+	t.evict()
+	if added && len(t.observers) != 0 {
+		t.notify(Insert, string(b))
+	}
+
+	return nil
+}
+
+// Same as Has, but decodes runes straight out of b rather than making
+// the caller convert it to a string first.
+func (t *Trie) HasBytes(b []byte) bool {
+	if t.fold || t.normalize {
+		return t.Has(string(b))
+	}
+	res := t.searchNodeBytes(b)
+	return res != nil && res.isEnd
+}
+
+// Same as HasPrefix, but decodes runes straight out of b rather than
+// making the caller convert it to a string first.
+func (t *Trie) HasPrefixBytes(b []byte) bool {
+	if t.fold || t.normalize {
+		return t.HasPrefix(string(b))
+	}
+	node := t.searchNodeBytes(b)
+	return node != nil && (node.isEnd || node.numChildren() != 0)
+}
@@ -0,0 +1,98 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTrieBytes(t *testing.T) {
+	putIn := []string{"", "abc", "de", "fghi", "acl", "été"}
+	query := []string{"", "ab", "abc", "abcd", "d", "ét", "été", "x", "a\xff"}
+
+	byString, byBytes := NewTrie(), NewTrie()
+	for _, n := range putIn {
+		byString.Put(n)
+		if err := byBytes.PutBytes([]byte(n)); err != nil {
+			t.Fatal("Unexpected error putting", n, err)
+		}
+	}
+	if !slices.Equal(byString.Keys(), byBytes.Keys()) || byString.Len() != byBytes.Len() {
+		t.Fatal("Expected PutBytes to give", byString.Keys(), "got", byBytes.Keys())
+	}
+
+	for _, n := range query {
+		if byString.Has(n) != byBytes.HasBytes([]byte(n)) {
+			t.Fatalf("Has and HasBytes disagree on %q", n)
+		}
+		if byString.HasPrefix(n) != byBytes.HasPrefixBytes([]byte(n)) {
+			t.Fatalf("HasPrefix and HasPrefixBytes disagree on %q", n)
+		}
+	}
+
+	if err := byBytes.PutBytes([]byte("bad\xff")); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+	if byBytes.HasPrefixBytes([]byte("bad")) {
+		t.Fatal("Expected a failed PutBytes to insert nothing")
+	}
+
+	fold := NewTrieFold()
+	fold.PutBytes([]byte("Apple"))
+	if !fold.HasBytes([]byte("APPLE")) || !fold.HasPrefixBytes([]byte("aPP")) {
+		t.Fatal("Expected the bytes methods to fold too")
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+var benchmarkBytesKeys = [][]byte{
+	[]byte("whatdidyousai"),
+	[]byte("invariant"),
+	[]byte("some other strin"),
+	[]byte("not in the trie"),
+}
+
+func BenchmarkTriePutHasString(b *testing.B) {
+	trie := NewTrie()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := benchmarkBytesKeys[i%len(benchmarkBytesKeys)]
+		if i%2 == 0 {
+			trie.Put(string(k))
+		} else {
+			trie.Has(string(k))
+		}
+	}
+}
+
+func BenchmarkTriePutHasBytes(b *testing.B) {
+	trie := NewTrie()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := benchmarkBytesKeys[i%len(benchmarkBytesKeys)]
+		if i%2 == 0 {
+			trie.PutBytes(k)
+		} else {
+			trie.HasBytes(k)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import "unicode"
+
+// A position in a Trie, for walking it one rune at a time from your own
+// code (e.g. a crossword solver trying letters). Made by Trie.Cursor.
+//
+// A Cursor points straight at the trie's nodes, so deleting strings from
+// the trie while a Cursor is in use may leave it somewhere that's no
+// longer part of the trie. Reset it after changing the trie. Release is
+// worse: for tries made by NewTrieArena, the nodes get reused, so a
+// Cursor kept across a Release ends up in the middle of whatever's put
+// next. Reset it after calling Release, too.
+//
+// Copying a Cursor (saved := *c) copies its position, which is handy for
+// backing up in a depth-first search.
+type Cursor struct {
+	trie *Trie
+	node *trieNode
+}
+
+// Returns a new Cursor at the root of the trie, i.e. at the empty string.
+//
+// Never returns nil.
+func (t *Trie) Cursor() *Cursor {
+	return &Cursor{trie: t, node: &t.root}
+}
+
+// Moves the cursor down to the child for r, the same as appending r to
+// the string it's at. In tries made with NewTrieFold, r is lowercased
+// first. NewTrieNormalized can't normalize one rune at a time, so with
+// those it's up to the caller to pass runes that are already in NFC.
+//
+// Returns false, and doesn't move, if nothing in the trie starts with
+// the string plus r.
+func (c *Cursor) Advance(r rune) bool {
+	if c.trie.fold {
+		r = unicode.ToLower(r)
+	}
+	child := c.node.child(r)
+	if child == nil {
+		return false
+	}
+	c.node = child
+	return true
+}
+
+// Reports whether the string the cursor is at is in the trie, rather
+// than just a prefix of something that is.
+func (c *Cursor) IsWord() bool {
+	return c.node.isEnd
+}
+
+// Returns how many runes the cursor can Advance by.
+func (c *Cursor) NumChildren() int {
+	return c.node.numChildren()
+}
+
+// Returns the runes the cursor can Advance by, in ascending order. The
+// slice is new each time, so the caller can keep or change it.
+//
+// Never returns nil.
+func (c *Cursor) ChildRunes() []rune {
+	return c.node.sortedRunes()
+}
+
+// Moves the cursor back to the root.
+func (c *Cursor) Reset() {
+	c.node = &c.trie.root
+}
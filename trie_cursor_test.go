@@ -0,0 +1,100 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTrieCursor(t *testing.T) {
+	trie := newTrieOf("cat", "car", "cart", "dog", "été")
+	c := trie.Cursor()
+	if c.IsWord() || c.NumChildren() != 3 {
+		t.Fatal("Expected the root to be a non-word with 3 children")
+	}
+
+	for _, r := range "car" {
+		if !c.Advance(r) {
+			t.Fatal("Expected to advance by", string(r))
+		}
+	}
+	if !c.IsWord() || c.NumChildren() != 1 {
+		t.Fatal("Expected car to be a word with 1 child")
+	}
+
+	// A failed Advance stays put.
+	if c.Advance('x') {
+		t.Fatal("Expected no child for x")
+	}
+	if !c.IsWord() || !c.Advance('t') || !c.IsWord() || c.NumChildren() != 0 {
+		t.Fatal("Expected to still be at car, then reach cart")
+	}
+
+	c.Reset()
+	for _, r := range "ét" {
+		if !c.Advance(r) {
+			t.Fatal("Expected to advance by", string(r))
+		}
+	}
+	if c.IsWord() || !c.Advance('é') || !c.IsWord() {
+		t.Fatal("Expected été to be a word, but not ét")
+	}
+}
+
+func TestTrieCursorFold(t *testing.T) {
+	trie := NewTrieFold()
+	trie.Put("Go")
+	c := trie.Cursor()
+	if !c.Advance('G') || !c.Advance('O') || !c.IsWord() {
+		t.Fatal("Expected a folding trie's cursor to fold runes")
+	}
+}
+
+func TestTrieCursorChildRunes(t *testing.T) {
+	// Enough children at the root to go past the small sorted slice.
+	trie := newTrieOf("zeta", "alpha", "mu", "beta", "é", "pi", "nu", "xi", "chi", "rho", "tau")
+	c := trie.Cursor()
+	expected := []rune("abcmnprtxzé")
+	if got := c.ChildRunes(); !slices.Equal(got, expected) {
+		t.Fatalf("Expected %q; got %q", string(expected), string(got))
+	}
+
+	// Every word can be found again by a depth-first search that only
+	// follows ChildRunes.
+	var found []string
+	var dfs func(c Cursor, prefix []rune)
+	dfs = func(c Cursor, prefix []rune) {
+		if c.IsWord() {
+			found = append(found, string(prefix))
+		}
+		for _, r := range c.ChildRunes() {
+			next := c
+			next.Advance(r)
+			dfs(next, append(prefix, r))
+		}
+	}
+	dfs(*c, nil)
+	if !slices.Equal(found, trie.Keys()) {
+		t.Fatal("Expected the search to find", trie.Keys(), "got", found)
+	}
+
+	c.Advance('é')
+	if got := c.ChildRunes(); got == nil || len(got) != 0 {
+		t.Fatal("Expected empty, non-nil runes at a leaf; got", got)
+	}
+}
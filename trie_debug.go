@@ -0,0 +1,116 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The most nodes String will print before giving up.
+const maxStringNodes = 1000
+
+// Writes one line per node at or below this node to b, children indented
+// two spaces past their parent, until budget lines have been written.
+//
+// Returns the remaining budget.
+func (t *trieNode) writeTree(b *strings.Builder, label string, depth, budget int) int {
+	if budget == 0 {
+		return 0
+	}
+	for i := 0; i < depth; i++ {
+		b.WriteString("  ")
+	}
+	b.WriteString(label)
+	if t.isEnd {
+		b.WriteString(" •")
+	}
+	b.WriteByte('\n')
+	budget--
+
+	for r, child := range t.sortedChildren() {
+		if budget == 0 {
+			break
+		}
+		budget = child.writeTree(b, string(r), depth+1, budget)
+	}
+	return budget
+}
+
+// Implements fmt.Stringer with a dump of the trie's structure: one rune
+// per line, children (in ascending order) indented under their parent,
+// and a " •" after every rune that ends a string. For example, a trie
+// holding "ab" and "ac" gives
+//
+//	root
+//	  a
+//	    b •
+//	    c •
+//
+// Meant for debugging small tries, so only the first 1000 nodes are
+// printed; if there are more, the output ends with a "..." line.
+func (t *Trie) String() string {
+	var b strings.Builder
+	if t.root.writeTree(&b, "root", 0, maxStringNodes+1) == 0 {
+		// Cut the one line past the limit back off, and say so.
+		out := b.String()
+		out = out[:strings.LastIndexByte(out[:len(out)-1], '\n')+1]
+		return out + "...\n"
+	}
+	return b.String()
+}
+
+// Quotes s as a Graphviz DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// Writes this node and everything below it to w as DOT statements,
+// numbering nodes in preorder starting from id.
+//
+// Returns the next unused id.
+func (t *trieNode) writeDot(w io.Writer, label string, id int) int {
+	shape := "circle"
+	if t.isEnd {
+		shape = "doublecircle"
+	}
+	fmt.Fprintf(w, "\tn%d [label=%s, shape=%s];\n", id, dotQuote(label), shape)
+
+	next := id + 1
+	for r, child := range t.sortedChildren() {
+		fmt.Fprintf(w, "\tn%d -> n%d;\n", id, next)
+		next = child.writeDot(w, string(r), next)
+	}
+	return next
+}
+
+// Writes the trie to w as a Graphviz digraph, e.g. for piping into
+// `dot -Tpng`. Every node is labeled with its rune ("root" for the
+// root) and has an edge to each of its children. Nodes that end a
+// string are drawn as double circles.
+//
+// Nodes are numbered in the order they're visited, with children in
+// ascending order, so the same trie always gives the same output.
+func (t *Trie) WriteDot(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph trie {")
+	t.root.writeDot(bw, "root", 0)
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
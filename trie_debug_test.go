@@ -0,0 +1,117 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTrieString(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"ac", "ab", "b", "abé"} {
+		trie.Put(n)
+	}
+
+	expected := "root\n" +
+		"  a\n" +
+		"    b •\n" +
+		"      é •\n" +
+		"    c •\n" +
+		"  b •\n"
+	if s := trie.String(); s != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, s)
+	}
+	if s := fmt.Sprint(trie); s != expected {
+		t.Fatal("Expected fmt to use String; got", s)
+	}
+
+	trie.Put("")
+	if s := trie.String(); !strings.HasPrefix(s, "root •\n") {
+		t.Fatal("Expected the root to be marked; got", s)
+	}
+
+	if s := NewTrie().String(); s != "root\n" {
+		t.Fatalf("Expected an empty trie to print just the root; got %q", s)
+	}
+}
+
+func TestTrieStringLimit(t *testing.T) {
+	trie := NewTrie()
+	for i := 0; i < 2*maxStringNodes; i++ {
+		trie.Put(fmt.Sprintf("%05d", i))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(trie.String(), "\n"), "\n")
+	if len(lines) != maxStringNodes+1 || lines[len(lines)-1] != "..." {
+		t.Fatal("Expected", maxStringNodes, "nodes and a ... line; got", len(lines), "lines ending in", lines[len(lines)-1])
+	}
+
+	// Exactly at the limit, nothing gets cut.
+	trie = NewTrie()
+	trie.Put(strings.Repeat("a", maxStringNodes-1))
+	if s := trie.String(); strings.HasSuffix(s, "...\n") {
+		t.Fatal("Didn't expect a trie of exactly", maxStringNodes, "nodes to be cut off")
+	}
+}
+
+func TestTrieWriteDot(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"ac", "ab", "\"", "ab\\"} {
+		trie.Put(n)
+	}
+
+	var buf bytes.Buffer
+	if err := trie.WriteDot(&buf); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	expected := `digraph trie {
+	n0 [label="root", shape=circle];
+	n0 -> n1;
+	n1 [label="\"", shape=doublecircle];
+	n0 -> n2;
+	n2 [label="a", shape=circle];
+	n2 -> n3;
+	n3 [label="b", shape=doublecircle];
+	n3 -> n4;
+	n4 [label="\\", shape=doublecircle];
+	n2 -> n5;
+	n5 [label="c", shape=doublecircle];
+}
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestTrieWriteDotError(t *testing.T) {
+	trie := NewTrie()
+	trie.Put("abc")
+	if err := trie.WriteDot(failingWriter{}); err == nil {
+		t.Fatal("Expected the writer's error to be returned")
+	}
+}
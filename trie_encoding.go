@@ -0,0 +1,244 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+var errCorruptEncoding = errors.New("Corrupt trie encoding")
+
+// Every trie written by Save starts with saveMagic followed by a single
+// version byte. Bump saveVersion if the node encoding ever changes.
+const (
+	saveMagic   = "GTRI"
+	saveVersion = 1
+)
+
+// Appends the encoding of this node and everything below it to buf.
+//
+// Nodes are written depth-first, in preorder, with children in
+// ascending order. Each node is its rune as a varint (skipped for the
+// root, which has no rune), its number of children as a uvarint, and a
+// byte that's 1 if a string ends at the node and 0 otherwise. Shared
+// prefixes are only written once.
+func (t *trieNode) appendEncoding(buf []byte, isRoot bool) []byte {
+	if !isRoot {
+		buf = binary.AppendVarint(buf, int64(t.value))
+	}
+	buf = binary.AppendUvarint(buf, uint64(t.numChildren()))
+	if t.isEnd {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	for _, child := range t.sortedChildren() {
+		buf = child.appendEncoding(buf, false)
+	}
+	return buf
+}
+
+// Reads the encoding of a node's children and end flag, as written by
+// appendEncoding, into node, which is depth runes below the root. The
+// node's own rune has already been consumed.
+func (t *Trie) decodeNode(r io.ByteReader, node *trieNode, depth int) error {
+	numChildren, err := binary.ReadUvarint(r)
+	if err != nil {
+		return errCorruptEncoding
+	}
+
+	end, err := r.ReadByte()
+	if err != nil || end > 1 {
+		return errCorruptEncoding
+	}
+	if depth > 0 && end == 0 && numChildren == 0 {
+		// appendEncoding never writes a node that no string goes through.
+		return errCorruptEncoding
+	}
+	if end == 1 {
+		t.markEnd(node)
+	}
+
+	for i := uint64(0); i < numChildren; i++ {
+		value, err := binary.ReadVarint(r)
+		if err != nil {
+			return errCorruptEncoding
+		}
+		rn := rune(value)
+		if int64(rn) != value || !utf8.ValidRune(rn) {
+			return errCorruptEncoding
+		}
+		if node.child(rn) != nil {
+			return errCorruptEncoding
+		}
+		if t.ascii && rn >= utf8.RuneSelf {
+			return ErrNotASCII
+		}
+		if depth == t.maxKeyRunes && t.maxKeyRunes > 0 {
+			// Whatever ends below here is too long.
+			return ErrKeyTooLong
+		}
+		if err := t.decodeNode(r, t.addChild(node, rn), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Builds the encoding made by appendEncoding into fresh, which should be
+// empty. All of the input must be consumed.
+func decodeTrie(data []byte, fresh *Trie) (*Trie, error) {
+	if fresh.fold || fresh.normalize {
+		// The encoding may come from a trie that doesn't fold or
+		// normalize, so its runes can't be trusted to be in canonical
+		// form. Decode it as-is, then put every key through Put.
+		raw, err := decodeTrie(data, NewTrie())
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range raw.Keys() {
+			if err := fresh.Put(k); err != nil {
+				return nil, err
+			}
+		}
+		return fresh, nil
+	}
+
+	r := bytes.NewReader(data)
+	if err := fresh.decodeNode(r, &fresh.root, 0); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errCorruptEncoding
+	}
+	return fresh, nil
+}
+
+// Implements encoding.BinaryMarshaler, using the compact node encoding
+// (see appendEncoding). Unlike Save, there's no header; the bytes are
+// just the nodes.
+func (t *Trie) MarshalBinary() ([]byte, error) {
+	return t.root.appendEncoding(nil, true), nil
+}
+
+// Implements encoding.BinaryUnmarshaler, replacing the contents of the
+// trie with the ones encoded by MarshalBinary. On error, the trie is left
+// untouched.
+func (t *Trie) UnmarshalBinary(data []byte) error {
+	fresh, err := decodeTrie(data, t.emptyCopy())
+	if err != nil {
+		return err
+	}
+	removed := t.wordsToNotify(&t.root)
+	t.root, t.size = fresh.root, fresh.size
+	t.root.adoptChildren()
+	t.rebuildOrder()
+	t.notifyAll(Delete, removed)
+	t.notifyAll(Insert, t.wordsToNotify(&t.root))
+	return nil
+}
+
+// Implements gob.GobEncoder, using the same encoding as MarshalBinary
+// rather than a list of strings.
+func (t *Trie) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+// Implements gob.GobDecoder; the same as UnmarshalBinary.
+func (t *Trie) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
+
+// Implements json.Marshaler. A trie is encoded as a JSON array of its
+// keys, in the same order as Keys.
+func (t *Trie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Keys())
+}
+
+// Implements json.Unmarshaler. Expects a JSON array of strings, as made
+// by MarshalJSON, and replaces the contents of the trie with them.
+//
+// Returns ErrInvalidUTF8 if any of the strings isn't valid utf8, or
+// ErrNotASCII if the trie only holds ascii and any of them isn't, or
+// ErrKeyTooLong if any of them is over the trie's rune limit. Every
+// string is checked before anything is replaced, so on error the trie is
+// left untouched.
+func (t *Trie) UnmarshalJSON(data []byte) error {
+	// encoding/json quietly swaps bad utf8 for U+FFFD when decoding a
+	// string, so the raw bytes have to be checked first.
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	keys := make([]string, len(raw))
+	for i, r := range raw {
+		if !utf8.Valid(r) {
+			return ErrInvalidUTF8
+		}
+		if err := json.Unmarshal(r, &keys[i]); err != nil {
+			return err
+		}
+		if err := t.checkPut(keys[i]); err != nil {
+			return err
+		}
+	}
+
+	t.Clear()
+	for _, k := range keys {
+		if err := t.Put(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the trie to w in a compact binary format that LoadTrie can
+// read back: a magic header, a version byte, and then every node
+// depth-first (see appendEncoding).
+func (t *Trie) Save(w io.Writer) error {
+	buf := append([]byte(saveMagic), saveVersion)
+	buf = t.root.appendEncoding(buf, true)
+	_, err := w.Write(buf)
+	return err
+}
+
+// Reads a trie written by Save. All of r is consumed.
+//
+// Returns an error if r doesn't start with the expected header, if it
+// was written by an unsupported version, or if the data is corrupt.
+func LoadTrie(r io.Reader) (*Trie, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header := len(saveMagic) + 1
+	if len(data) < header || string(data[:len(saveMagic)]) != saveMagic {
+		return nil, errors.New("Not a saved trie: missing magic header")
+	}
+	if v := data[len(saveMagic)]; v != saveVersion {
+		return nil, fmt.Errorf("Unsupported saved trie version %d (expected %d)", v, saveVersion)
+	}
+	return decodeTrie(data[header:], NewTrie())
+}
@@ -0,0 +1,250 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrieJSON(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"mlp", "abc", "", "ab", "été"} {
+		trie.Put(n)
+	}
+
+	data, err := json.Marshal(trie)
+	if err != nil {
+		t.Fatal("Unexpected error marshaling:", err)
+	}
+	if expected := `["","ab","abc","mlp","été"]`; string(data) != expected {
+		t.Fatal("Expected", expected, "got", string(data))
+	}
+
+	decoded := NewTrie()
+	decoded.Put("stale")
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal("Unexpected error unmarshaling:", err)
+	}
+	if !slices.Equal(decoded.Keys(), trie.Keys()) || decoded.Len() != trie.Len() {
+		t.Fatal("Expected round trip to give", trie.Keys(), "got", decoded.Keys())
+	}
+
+	empty, err := json.Marshal(NewTrie())
+	if err != nil || string(empty) != "[]" {
+		t.Fatal("Expected an empty trie to marshal to []; got", string(empty), err)
+	}
+}
+
+func TestTrieJSONErrors(t *testing.T) {
+	trie := NewTrie()
+	trie.Put("keep")
+
+	err := json.Unmarshal([]byte("[\"ok\", \"bad\xff\"]"), trie)
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"not": "an array"}`), trie); err == nil {
+		t.Fatal("Expected an error unmarshaling an object")
+	}
+
+	if keys := trie.Keys(); !slices.Equal(keys, []string{"keep"}) {
+		t.Fatal("Expected failed unmarshals to leave the trie alone; got", keys)
+	}
+}
+
+func TestTrieJSONNotASCII(t *testing.T) {
+	trie := NewTrieASCII()
+	trie.Put("keep")
+	trie.Put("old")
+
+	err := json.Unmarshal([]byte(`["a", "é"]`), trie)
+	if !errors.Is(err, ErrNotASCII) {
+		t.Fatal("Expected ErrNotASCII; got", err)
+	}
+	if keys := trie.Keys(); !slices.Equal(keys, []string{"keep", "old"}) {
+		t.Fatal("Expected a failed unmarshal to leave the trie alone; got", keys)
+	}
+}
+
+func TestTrieGob(t *testing.T) {
+	trie := NewTrie()
+	putIn := []string{"", "abc", "abd", "de", "fghi", "acl", "été", "\U0001F600"}
+	for _, n := range putIn {
+		trie.Put(n)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(trie); err != nil {
+		t.Fatal("Unexpected error encoding:", err)
+	}
+
+	decoded := NewTrie()
+	decoded.Put("stale")
+	if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+		t.Fatal("Unexpected error decoding:", err)
+	}
+
+	for _, n := range putIn {
+		if !decoded.Has(n) {
+			t.Fatal("Expected decoded trie to have", n)
+		}
+	}
+	for _, n := range []string{"stale", "ab", "d", "fgh", "abcd"} {
+		if decoded.Has(n) {
+			t.Fatal("Expected decoded trie to not have", n)
+		}
+	}
+	if !slices.Equal(decoded.Keys(), trie.Keys()) || decoded.Len() != trie.Len() {
+		t.Fatal("Expected round trip to give", trie.Keys(), "got", decoded.Keys())
+	}
+}
+
+func TestTrieMarshalBinary(t *testing.T) {
+	var _ encoding.BinaryMarshaler = NewTrie()
+	var _ encoding.BinaryUnmarshaler = NewTrie()
+
+	trie := newTrieOf("", "abc", "abd", "de", "été", "\U0001F600")
+	data, err := trie.MarshalBinary()
+	if err != nil {
+		t.Fatal("Unexpected error encoding:", err)
+	}
+	if gobData, _ := trie.GobEncode(); !bytes.Equal(data, gobData) {
+		t.Fatal("Expected the same encoding as GobEncode")
+	}
+
+	decoded := newTrieOf("stale")
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal("Unexpected error decoding:", err)
+	}
+	if !decoded.Equal(trie) {
+		t.Fatal("Expected round trip to give", trie.Keys(), "got", decoded.Keys())
+	}
+
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Fatal("Expected an error decoding truncated data")
+	}
+	if !decoded.Equal(trie) {
+		t.Fatal("Expected a failed decode to leave the trie alone")
+	}
+}
+
+func TestTrieMarshalBinaryCanonical(t *testing.T) {
+	data, _ := newTrieOf("Apple", "e\u0301").MarshalBinary()
+
+	fold := NewTrieFold()
+	if err := fold.UnmarshalBinary(data); err != nil {
+		t.Fatal("Unexpected error decoding into a fold trie:", err)
+	}
+	if !fold.Has("Apple") || !fold.Has("apple") {
+		t.Fatal("Expected a fold trie to find decoded keys in any case; got", fold.Keys())
+	}
+	fold.Put("apple")
+	if fold.Len() != 2 {
+		t.Fatal("Expected putting a decoded key again not to add it; got", fold.Keys())
+	}
+
+	normalized := NewTrieNormalized()
+	if err := normalized.UnmarshalBinary(data); err != nil {
+		t.Fatal("Unexpected error decoding into a normalized trie:", err)
+	}
+	if !normalized.Has("\u00e9") || !normalized.Has("e\u0301") {
+		t.Fatal("Expected a normalized trie to find decoded keys in either form; got", normalized.Keys())
+	}
+	if keys := normalized.Keys(); !slices.Equal(keys, []string{"Apple", "\u00e9"}) {
+		t.Fatal("Expected decoded keys to be stored in NFC; got", keys)
+	}
+}
+
+func TestTrieGobDecodeErrors(t *testing.T) {
+	trie := NewTrie()
+	trie.Put("ab")
+	trie.Put("ac")
+	good, _ := trie.GobEncode()
+
+	corrupt := [][]byte{
+		{},
+		good[:len(good)-1],
+		append(slices.Clone(good), 0),
+		// Root with one child whose end flag is 2.
+		{1, 0, 2, 0, 2},
+		// Root with two children, both 'a'.
+		{2, 0, 0xc2, 0x01, 0, 1, 0xc2, 0x01, 0, 1},
+		// Root with one child that has no children and isn't an end.
+		{1, 0, 0xc2, 0x01, 0, 0},
+	}
+	for _, data := range corrupt {
+		decoded := NewTrie()
+		decoded.Put("keep")
+		if err := decoded.GobDecode(data); err == nil {
+			t.Fatalf("Expected an error decoding %v", data)
+		}
+		if keys := decoded.Keys(); !slices.Equal(keys, []string{"keep"}) {
+			t.Fatal("Expected a failed decode to leave the trie alone; got", keys)
+		}
+	}
+}
+
+func TestTrieSaveLoad(t *testing.T) {
+	trie := NewTrie()
+	putIn := []string{"", "abc", "abd", "de", "été"}
+	for _, n := range putIn {
+		trie.Put(n)
+	}
+
+	var buf bytes.Buffer
+	if err := trie.Save(&buf); err != nil {
+		t.Fatal("Unexpected error saving:", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("GTRI\x01")) {
+		t.Fatal("Expected saved trie to start with the header; got", buf.Bytes())
+	}
+
+	loaded, err := LoadTrie(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal("Unexpected error loading:", err)
+	}
+	if !slices.Equal(loaded.Keys(), trie.Keys()) || loaded.Len() != trie.Len() {
+		t.Fatal("Expected round trip to give", trie.Keys(), "got", loaded.Keys())
+	}
+
+	saved := buf.Bytes()
+	badMagic := append([]byte("GTRX"), saved[4:]...)
+	if _, err := LoadTrie(bytes.NewReader(badMagic)); err == nil || !strings.Contains(err.Error(), "magic") {
+		t.Fatal("Expected a magic header error; got", err)
+	}
+
+	badVersion := slices.Clone(saved)
+	badVersion[4] = 2
+	if _, err := LoadTrie(bytes.NewReader(badVersion)); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Fatal("Expected a version error; got", err)
+	}
+
+	if _, err := LoadTrie(bytes.NewReader(saved[:len(saved)-1])); err == nil {
+		t.Fatal("Expected an error loading a truncated trie")
+	}
+	if _, err := LoadTrie(strings.NewReader("GT")); err == nil {
+		t.Fatal("Expected an error loading a truncated header")
+	}
+}
@@ -0,0 +1,190 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// Adds one to the number of times s has been seen, putting it in the
+// trie first if it isn't there yet. A string that's only ever been Put
+// has been seen once.
+//
+// Counts are only kept in memory; tries that go through any of the
+// encodings come back with every count at 1.
+//
+// Returns the same errors as Put, in which case nothing is counted.
+func (t *Trie) Add(s string) error {
+	node, added, err := t.insert(s)
+	if err == nil && !added {
+		node.count++
+	}
+	return err
+}
+
+// Same as calling Add n times, but in one pass. If n is negative, s is
+// taken away n times instead; once it's been taken away as many times as
+// it was added (or more, since counts never go below zero), it's deleted
+// just like with Delete. Taking away a string that isn't in the trie does
+// nothing, and so does n == 0.
+//
+// Returns the same errors as Put, in which case nothing changes.
+func (t *Trie) PutN(s string, n int) error {
+	if n > 0 {
+		node, added, err := t.insert(s)
+		if err == nil {
+			if added {
+				node.count = n
+			} else {
+				node.count += n
+			}
+		}
+		return err
+	}
+
+	if err := t.checkPut(s); err != nil {
+		return err
+	}
+	node := t.searchNode(t.canonical(s))
+	if node == nil || !node.isEnd {
+		return nil
+	}
+	node.count += n
+	if node.count <= 0 {
+		t.unmarkEnd(node)
+	}
+	return nil
+}
+
+// Returns the number of times s has been added (see Add and PutN), or 0
+// if s isn't in the trie. Prefixes of strings in the trie that aren't in
+// it themselves count as 0 too.
+func (t *Trie) Count(s string) int {
+	s = t.canonical(s)
+	node := t.searchNode(s)
+	if node == nil {
+		return 0
+	}
+	return node.count
+}
+
+// A string and the number of times it was added to a trie.
+type wordCount struct {
+	Word  string
+	Count int
+}
+
+// Reports whether a ranks below b: fewer adds, or the same number of adds
+// and later in lexicographic order.
+func (a wordCount) less(b wordCount) bool {
+	if a.Count != b.Count {
+		return a.Count < b.Count
+	}
+	return a.Word > b.Word
+}
+
+// A min-heap of wordCounts, with the lowest ranked on top.
+type wordCountHeap []wordCount
+
+func (h wordCountHeap) Len() int           { return len(h) }
+func (h wordCountHeap) Less(i, j int) bool { return h[i].less(h[j]) }
+func (h wordCountHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *wordCountHeap) Push(x any)        { *h = append(*h, x.(wordCount)) }
+func (h *wordCountHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Returns the n highest ranked strings at or below this node, highest
+// first: most adds first, with ties broken lexicographically. Only n
+// strings are held at a time, no matter how many are below the node.
+func (t *trieNode) topCounts(prefix []rune, n int) []wordCount {
+	if n <= 0 {
+		return []wordCount{}
+	}
+
+	h := make(wordCountHeap, 0, n)
+	t.walkEnds(prefix, func(word []rune, node *trieNode) bool {
+		if len(h) < n {
+			heap.Push(&h, wordCount{string(word), node.count})
+			return true
+		}
+		// Strings come in lexicographic order, so a tie with the lowest
+		// ranked string we have always loses.
+		if node.count > h[0].Count {
+			h[0] = wordCount{string(word), node.count}
+			heap.Fix(&h, 0)
+		}
+		return true
+	})
+
+	slices.SortFunc(h, func(a, b wordCount) int {
+		if b.less(a) {
+			return -1
+		}
+		return 1
+	})
+	return h
+}
+
+// Returns the n completions of prefix (strings starting with it,
+// including itself) that have been added the most, most-added first.
+// Completions added the same number of times come out in lexicographic
+// order.
+//
+// Never returns nil; if n <= 0 or nothing starts with prefix, an empty
+// slice is returned.
+func (t *Trie) TopCompletions(prefix string, n int) []string {
+	prefix = t.canonical(prefix)
+	node := t.searchNode(prefix)
+	if node == nil || n <= 0 {
+		return []string{}
+	}
+
+	top := node.topCounts([]rune(prefix), n)
+	out := make([]string, len(top))
+	for i, wc := range top {
+		out[i] = wc.Word
+	}
+	return out
+}
+
+// Returns the k strings in the trie that have been added the most, along
+// with how many times each was added, most-added first. Strings added the
+// same number of times come out in lexicographic order. If k is more than
+// Len, every string is returned.
+//
+// Only k strings are held at a time, no matter how big the trie is.
+//
+// Never returns nil.
+func (t *Trie) TopK(k int) []struct {
+	Word  string
+	Count int
+} {
+	top := t.root.topCounts(nil, k)
+	out := make([]struct {
+		Word  string
+		Count int
+	}, len(top))
+	for i, wc := range top {
+		out[i] = wc
+	}
+	return out
+}
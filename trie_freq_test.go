@@ -0,0 +1,196 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTrieAddTopCompletions(t *testing.T) {
+	trie := NewTrie()
+	adds := map[string]int{
+		"car":    5,
+		"cart":   2,
+		"carbon": 2,
+		"cat":    7,
+		"cab":    2,
+		"dog":    9,
+	}
+	for w, n := range adds {
+		for i := 0; i < n; i++ {
+			if err := trie.Add(w); err != nil {
+				t.Fatal("Unexpected error adding", w, err)
+			}
+		}
+	}
+	// Put counts as being seen once, and doesn't bump existing words.
+	trie.Put("cattle")
+	trie.Put("car")
+
+	if trie.Len() != len(adds)+1 {
+		t.Fatal("Expected Add to count each word once in Len; got", trie.Len())
+	}
+
+	cases := []struct {
+		prefix string
+		n      int
+		top    []string
+	}{
+		{"ca", 3, []string{"cat", "car", "cab"}},
+		// Ties on 2 break lexicographically.
+		{"ca", 5, []string{"cat", "car", "cab", "carbon", "cart"}},
+		{"car", 10, []string{"car", "carbon", "cart"}},
+		{"", 1, []string{"dog"}},
+		{"cat", 2, []string{"cat", "cattle"}},
+		{"ca", 0, []string{}},
+		{"x", 3, []string{}},
+	}
+	for _, c := range cases {
+		top := trie.TopCompletions(c.prefix, c.n)
+		if top == nil || !slices.Equal(top, c.top) {
+			t.Fatalf("TopCompletions(%q, %d): expected %v, got %v", c.prefix, c.n, c.top, top)
+		}
+	}
+
+	// Deleting resets the count.
+	trie.Delete("dog")
+	trie.Add("dog")
+	if top := trie.TopCompletions("d", 1); !slices.Equal(top, []string{"dog"}) {
+		t.Fatal("Expected dog to come back; got", top)
+	}
+	if top := trie.TopCompletions("", 2); !slices.Equal(top, []string{"cat", "car"}) {
+		t.Fatal("Expected dog's count to have been reset; got", top)
+	}
+
+	if err := trie.Add("bad\xff"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+}
+
+func TestTrieTopK(t *testing.T) {
+	trie := NewTrie()
+	for _, w := range []string{"b", "a", "c", "b", "d", "c", "b", ""} {
+		trie.Add(w)
+	}
+
+	type wc = struct {
+		Word  string
+		Count int
+	}
+	top := trie.TopK(3)
+	if expected := []wc{{"b", 3}, {"c", 2}, {"", 1}}; !slices.Equal(top, expected) {
+		t.Fatal("Expected", expected, "got", top)
+	}
+
+	top = trie.TopK(100)
+	if expected := []wc{{"b", 3}, {"c", 2}, {"", 1}, {"a", 1}, {"d", 1}}; !slices.Equal(top, expected) {
+		t.Fatal("Expected", expected, "got", top)
+	}
+
+	if top := trie.TopK(0); top == nil || len(top) != 0 {
+		t.Fatal("Expected an empty slice for k = 0; got", top)
+	}
+	if top := NewTrie().TopK(5); top == nil || len(top) != 0 {
+		t.Fatal("Expected an empty slice for an empty trie; got", top)
+	}
+}
+
+func TestTriePutN(t *testing.T) {
+	trie := NewTrie()
+	trie.PutN("apple", 5)
+	trie.PutN("apricot", 3)
+	trie.Add("apricot")
+	trie.PutN("avocado", 0)
+
+	if trie.Len() != 2 || trie.Has("avocado") {
+		t.Fatal("Expected PutN with 0 to add nothing; got", trie.Keys())
+	}
+	type wc = struct {
+		Word  string
+		Count int
+	}
+	if top := trie.TopK(2); !slices.Equal(top, []wc{{"apple", 5}, {"apricot", 4}}) {
+		t.Fatal("Unexpected counts after PutN:", top)
+	}
+
+	trie.PutN("apple", -2)
+	if top := trie.TopK(2); !slices.Equal(top, []wc{{"apricot", 4}, {"apple", 3}}) {
+		t.Fatal("Unexpected counts after taking away apple:", top)
+	}
+
+	// Hitting zero deletes, and so does going past it.
+	trie.PutN("apple", -3)
+	trie.PutN("apricot", -100)
+	if !trie.IsEmpty() || trie.NodeCount() != 0 {
+		t.Fatal("Expected everything to be deleted; got", trie.Keys())
+	}
+
+	// Taking away something missing does nothing.
+	trie.Put("ap")
+	if err := trie.PutN("apple", -1); err != nil || trie.Len() != 1 || trie.HasPrefix("apple") {
+		t.Fatal("Expected taking away a missing word to do nothing; got", err, trie.Keys())
+	}
+	if err := trie.PutN("a", -1); err != nil || !trie.Has("ap") {
+		t.Fatal("Expected taking away a prefix to do nothing; got", err, trie.Keys())
+	}
+
+	for _, n := range []int{-1, 0, 1} {
+		if err := trie.PutN("bad\xff", n); !errors.Is(err, ErrInvalidUTF8) {
+			t.Fatal("Expected ErrInvalidUTF8 for n =", n, "got", err)
+		}
+	}
+	if err := NewTrieASCII().PutN("\u00e9", -1); !errors.Is(err, ErrNotASCII) {
+		t.Fatal("Expected ErrNotASCII; got", err)
+	}
+}
+
+func TestTrieCount(t *testing.T) {
+	trie := NewTrie()
+	trie.PutN("there", 4)
+	trie.Add("the")
+	trie.Put("them")
+	trie.Put("them")
+
+	counts := map[string]int{
+		"there":  4,
+		"the":    1,
+		"them":   1,
+		"th":     0,
+		"":       0,
+		"theres": 0,
+		"\xff":   0,
+	}
+	for s, n := range counts {
+		if c := trie.Count(s); c != n {
+			t.Fatalf("Count(%q): expected %d, got %d", s, n, c)
+		}
+	}
+
+	trie.Delete("there")
+	if c := trie.Count("there"); c != 0 {
+		t.Fatal("Expected a deleted word to count 0; got", c)
+	}
+
+	fold := NewTrieFold()
+	fold.Add("Go")
+	fold.Add("GO")
+	if c := fold.Count("go"); c != 2 {
+		t.Fatal("Expected folded adds to count together; got", c)
+	}
+}
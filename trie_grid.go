@@ -0,0 +1,85 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"unicode"
+)
+
+// Finds every string in the trie, at least minLen runes long, that can
+// be spelled out on grid Boggle-style: start on any cell, then keep
+// stepping to one of the (up to 8) neighbouring cells, horizontally,
+// vertically or diagonally, using each cell at most once per string.
+// Rows may differ in length. Paths are abandoned as soon as they stop
+// being a prefix of anything in the trie, so most of the board is never
+// explored.
+//
+// In tries made with NewTrieFold, the letters on the grid are lowercased
+// first. As with Cursor, normalization is up to the caller.
+//
+// Returns each string once, in the same order as Keys. Never returns
+// nil.
+func (t *Trie) FindInGrid(grid [][]rune, minLen int) []string {
+	used := make([][]bool, len(grid))
+	for i, row := range grid {
+		used[i] = make([]bool, len(row))
+	}
+
+	found := map[string]bool{}
+	var path []rune
+	var search func(i, j int, node *trieNode)
+	search = func(i, j int, node *trieNode) {
+		r := grid[i][j]
+		if t.fold {
+			r = unicode.ToLower(r)
+		}
+		node = node.child(r)
+		if node == nil {
+			return
+		}
+
+		used[i][j] = true
+		path = append(path, r)
+		if node.isEnd && len(path) >= minLen {
+			found[string(path)] = true
+		}
+		for di := -1; di <= 1; di++ {
+			for dj := -1; dj <= 1; dj++ {
+				ni, nj := i+di, j+dj
+				if ni >= 0 && ni < len(grid) && nj >= 0 && nj < len(grid[ni]) && !used[ni][nj] {
+					search(ni, nj, node)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		used[i][j] = false
+	}
+
+	for i, row := range grid {
+		for j := range row {
+			search(i, j, &t.root)
+		}
+	}
+
+	out := make([]string, 0, len(found))
+	for s := range found {
+		out = append(out, s)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,66 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"testing"
+)
+
+func gridOf(rows ...string) [][]rune {
+	grid := make([][]rune, len(rows))
+	for i, row := range rows {
+		grid[i] = []rune(row)
+	}
+	return grid
+}
+
+func TestTrieFindInGrid(t *testing.T) {
+	grid := gridOf(
+		"cat",
+		"ore",
+		"dgé",
+	)
+	trie := newTrieOf("cat", "cot", "dog", "cog", "rate", "tree", "code", "toe", "été", "rot", "a", "", "gé", "cart")
+
+	// tree needs e twice and été needs é twice; code, cot, rot and toe
+	// need o and t to be neighbours, which they aren't.
+	expected := []string{"a", "cart", "cat", "cog", "dog", "gé", "rate"}
+	if got := trie.FindInGrid(grid, 0); !slices.Equal(got, expected) {
+		t.Fatal("Expected", expected, "got", got)
+	}
+
+	expected = []string{"cart", "rate"}
+	if got := trie.FindInGrid(grid, 4); !slices.Equal(got, expected) {
+		t.Fatal("Expected", expected, "with minLen 4; got", got)
+	}
+
+	if got := trie.FindInGrid(nil, 0); got == nil || len(got) != 0 {
+		t.Fatal("Expected empty, non-nil results for an empty grid; got", got)
+	}
+}
+
+func TestTrieFindInGridRagged(t *testing.T) {
+	trie := NewTrieFold()
+	trie.Put("abc")
+	trie.Put("aba")
+	// a b
+	// c
+	if got := trie.FindInGrid(gridOf("AB", "C"), 1); !slices.Equal(got, []string{"abc"}) {
+		t.Fatal("Expected only abc, once; got", got)
+	}
+}
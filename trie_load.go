@@ -0,0 +1,162 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"unicode/utf8"
+)
+
+// Creates a new Trie holding every string in words.
+//
+// If any word fails to Put (i.e. has invalid utf8 in it), returns nil
+// and the first such error, wrapped with the word's index. The wrapped
+// error can be checked with errors.Is.
+func NewTrieFromSlice(words []string) (*Trie, error) {
+	t := NewTrie()
+	for i, w := range words {
+		if err := t.Put(w); err != nil {
+			return nil, fmt.Errorf("word %d: %w", i, err)
+		}
+	}
+	return t, nil
+}
+
+// Creates a new Trie holding every string in words, like
+// NewTrieFromSlice, but builds it with shards goroutines. Words are split
+// between shards by their first rune, so each shard builds its own part
+// of the trie without locking, and the parts are joined at the root at
+// the end. If shards < 1, GOMAXPROCS shards are used.
+//
+// Only worth it for large slices on machines with several cores; words
+// that mostly start with the same rune all land in one shard.
+//
+// Words that Put rejects (i.e. that have invalid utf8 in them) are left
+// out. Use NewTrieFromSlice to have them reported instead.
+//
+// Never returns nil.
+func NewTrieConcurrent(words []string, shards int) *Trie {
+	if shards < 1 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+
+	t := NewTrie()
+	parts := make([][]string, shards)
+	for _, w := range words {
+		if w == "" {
+			// The root isn't part of any shard.
+			t.markEnd(&t.root)
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(w)
+		shard := int(uint32(r) % uint32(shards))
+		parts[shard] = append(parts[shard], w)
+	}
+
+	tries := make([]*Trie, shards)
+	var wg sync.WaitGroup
+	for shard, part := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tries[shard] = NewTrie()
+			for _, w := range part {
+				tries[shard].Put(w)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Shards never share a first rune, so their roots' children can be
+	// moved over as they are.
+	for _, part := range tries {
+		for r, child := range part.root.children() {
+			child.parent = &t.root
+			t.root.setChild(r, child)
+		}
+		t.size += part.size
+		t.root.ends += part.root.ends
+	}
+	return t
+}
+
+// Creates a new Trie holding every line read from r, e.g. from
+// /usr/share/dict/words. Line endings ("\n" or "\r\n") aren't part of
+// the words. Blank lines are skipped rather than being put in as the
+// empty string.
+//
+// If a line fails to Put (i.e. has invalid utf8 in it), returns nil and
+// the error, wrapped with the line number (starting from 1). Errors
+// reading from r are returned as is.
+func NewTrieFromReader(r io.Reader) (*Trie, error) {
+	t := NewTrie()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		w := scanner.Text()
+		if len(w) == 0 {
+			continue
+		}
+		if err := t.Put(w); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Puts every line read from r into the trie, like NewTrieFromReader, but
+// carries on past lines that fail to Put: each one is handed to onError,
+// along with its line number (starting from 1) and the error. If onError
+// returns false, or is nil, loading stops and that error is returned,
+// wrapped with the line number. Lines can be up to
+// bufio.MaxScanTokenSize bytes long; see LoadLinesMax.
+//
+// Returns how many strings were added that weren't already in the trie,
+// even when stopping early. Errors reading from r are returned as is.
+func (t *Trie) LoadLines(r io.Reader, onError func(line int, s string, err error) bool) (inserted int, err error) {
+	return t.LoadLinesMax(r, bufio.MaxScanTokenSize, onError)
+}
+
+// Same as LoadLines, but lines can be up to maxLine bytes long. A longer
+// line stops loading with bufio.ErrTooLong.
+func (t *Trie) LoadLinesMax(r io.Reader, maxLine int, onError func(line int, s string, err error) bool) (inserted int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	for line := 1; scanner.Scan(); line++ {
+		w := scanner.Text()
+		if len(w) == 0 {
+			continue
+		}
+		added, err := t.PutNew(w)
+		if err != nil {
+			if onError == nil || !onError(line, w, err) {
+				return inserted, fmt.Errorf("line %d: %w", line, err)
+			}
+			continue
+		}
+		if added {
+			inserted++
+		}
+	}
+	return inserted, scanner.Err()
+}
@@ -0,0 +1,194 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bufio"
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewTrieFromSlice(t *testing.T) {
+	words := []string{"banana", "apple", "", "apple"}
+	trie, err := NewTrieFromSlice(words)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if keys := trie.Keys(); !slices.Equal(keys, []string{"", "apple", "banana"}) {
+		t.Fatal("Unexpected keys:", keys)
+	}
+
+	trie, err = NewTrieFromSlice([]string{"ok", "fine", "bad\xff", "worse\xfe"})
+	if trie != nil || !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", trie, err)
+	}
+	if !strings.Contains(err.Error(), "word 2") {
+		t.Fatal("Expected the error to name word 2; got", err)
+	}
+}
+
+func TestNewTrieConcurrent(t *testing.T) {
+	rand.Seed(0) // Arbitrary seed
+	alphabet := []rune("abcdeé日")
+	words := []string{""}
+	for i := 0; i < 5000; i++ {
+		buf := make([]rune, rand.Intn(6))
+		for j := range buf {
+			buf[j] = alphabet[rand.Intn(len(alphabet))]
+		}
+		words = append(words, string(buf))
+	}
+	expected, _ := NewTrieFromSlice(words)
+
+	for _, shards := range []int{0, 1, 3, 16} {
+		trie := NewTrieConcurrent(words, shards)
+		if !trie.Equal(expected) || trie.Len() != expected.Len() {
+			t.Fatal("Expected the same contents as NewTrieFromSlice with", shards, "shards")
+		}
+		checkParents(t, &trie.root)
+	}
+
+	// Bad words are left out, whichever shard they're in.
+	trie := NewTrieConcurrent([]string{"ok", "z\xff", "a\xff", "\xfe", "zz"}, 4)
+	if keys := trie.Keys(); !slices.Equal(keys, []string{"ok", "zz"}) {
+		t.Fatal("Expected just the good words; got", keys)
+	}
+	checkParents(t, &trie.root)
+}
+
+func TestNewTrieFromReader(t *testing.T) {
+	input := "banana\napple\r\n\n  spaced  \nlast"
+	trie, err := NewTrieFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []string{"  spaced  ", "apple", "banana", "last"}
+	if keys := trie.Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+
+	trie, err = NewTrieFromReader(strings.NewReader("ok\n\nbad\xff\n"))
+	if trie != nil || !errors.Is(err, ErrInvalidUTF8) || !strings.Contains(err.Error(), "line 3") {
+		t.Fatal("Expected ErrInvalidUTF8 on line 3; got", err)
+	}
+
+	trie, err = NewTrieFromReader(failingReader{})
+	if trie != nil || err == nil {
+		t.Fatal("Expected the reader's error; got", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestTrieLoadLines(t *testing.T) {
+	input := "apple\nba\xffd\r\n\nbanana\napple\n\xfe\ncherry"
+	trie := newTrieOf("banana")
+
+	type badLine struct {
+		line int
+		s    string
+	}
+	var bad []badLine
+	inserted, err := trie.LoadLines(strings.NewReader(input), func(line int, s string, err error) bool {
+		if !errors.Is(err, ErrInvalidUTF8) {
+			t.Fatal("Expected ErrInvalidUTF8 for line", line, "got", err)
+		}
+		bad = append(bad, badLine{line, s})
+		return true
+	})
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	// banana was already there, and apple shows up twice.
+	if inserted != 2 {
+		t.Fatal("Expected 2 strings inserted; got", inserted)
+	}
+	if expected := []badLine{{2, "ba\xffd"}, {6, "\xfe"}}; !slices.Equal(bad, expected) {
+		t.Fatal("Expected bad lines", expected, "got", bad)
+	}
+	if expected := []string{"apple", "banana", "cherry"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected", expected, "got", trie.Keys())
+	}
+
+	// Stopping at the first bad line.
+	trie = NewTrie()
+	inserted, err = trie.LoadLines(strings.NewReader(input), func(int, string, error) bool {
+		return false
+	})
+	if inserted != 1 || !errors.Is(err, ErrInvalidUTF8) || err.Error() != "line 2: "+ErrInvalidUTF8.Error() {
+		t.Fatal("Expected to stop at line 2 after 1 insert; got", inserted, err)
+	}
+	if _, err := NewTrie().LoadLines(strings.NewReader(input), nil); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected a nil onError to stop at the first bad line; got", err)
+	}
+
+	if _, err := NewTrie().LoadLines(failingReader{}, nil); err == nil {
+		t.Fatal("Expected the reader's error")
+	}
+}
+
+func TestTrieLoadLinesMax(t *testing.T) {
+	trie := NewTrie()
+	inserted, err := trie.LoadLinesMax(strings.NewReader("short\nmuch too long\nok"), 8, nil)
+	if inserted != 1 || !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatal("Expected bufio.ErrTooLong after 1 insert; got", inserted, err)
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+func benchmarkBulkLoad(b *testing.B, load func([]string) (*Trie, error)) {
+	const NUM_STRINGS = 1000000
+	const STR_LEN = 10
+	const NUM_CHRS = 94
+	const OFFSET = 32
+
+	rand.Seed(0) // Arbitrary seed
+
+	words := make([]string, NUM_STRINGS)
+	buf := make([]rune, STR_LEN)
+	for i := range words {
+		for x := range buf {
+			buf[x] = rune(rand.Int31n(NUM_CHRS) + OFFSET)
+		}
+		words[i] = string(buf)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := load(words); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewTrieFromSlice(b *testing.B) {
+	benchmarkBulkLoad(b, NewTrieFromSlice)
+}
+
+func BenchmarkNewTrieConcurrent(b *testing.B) {
+	benchmarkBulkLoad(b, func(words []string) (*Trie, error) {
+		return NewTrieConcurrent(words, 0), nil
+	})
+}
@@ -0,0 +1,114 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"container/list"
+	"slices"
+)
+
+// Creates a new Trie for the user that holds at most capacity strings.
+// Putting a new string into a full trie first deletes the string that was
+// put longest ago, same as Delete would. Putting a string that's already
+// there counts as putting it again, and makes it the newest.
+//
+// Lookups (Has, HasPrefix, Keys and so on) don't count, so they never
+// change which string goes next, and stay safe to run concurrently under
+// a read lock.
+//
+// Each string costs an extra list element and map entry to keep track
+// of. A capacity < 1 means there's no cap, same as NewTrie.
+//
+// Never returns nil.
+func NewTrieCapped(capacity int) *Trie {
+	t := NewTrie()
+	if capacity > 0 {
+		t.setCapacity(capacity)
+	}
+	return t
+}
+
+// Returns the most strings the trie will hold, or 0 if there's no cap.
+func (t *Trie) Capacity() int {
+	return t.capacity
+}
+
+// Caps the trie at capacity (> 0) strings, and forgets the order
+// everything was put in.
+func (t *Trie) setCapacity(capacity int) {
+	t.capacity = capacity
+	t.order = list.New()
+	t.orderOf = make(map[*trieNode]*list.Element)
+}
+
+// Makes node, which is or is about to be the end of a string, the most
+// recently put. Does nothing for tries without a cap.
+func (t *Trie) touch(node *trieNode) {
+	if t.capacity <= 0 {
+		return
+	}
+	if e, ok := t.orderOf[node]; ok {
+		t.order.MoveToBack(e)
+	} else {
+		t.orderOf[node] = t.order.PushBack(node)
+	}
+}
+
+// Drops node, which is no longer the end of a string, from the order.
+func (t *Trie) forget(node *trieNode) {
+	if t.capacity <= 0 {
+		return
+	}
+	if e, ok := t.orderOf[node]; ok {
+		t.order.Remove(e)
+		delete(t.orderOf, node)
+	}
+}
+
+// Deletes the oldest strings until the trie's back under its cap.
+func (t *Trie) evict() {
+	for t.capacity > 0 && t.size > t.capacity {
+		t.unmarkEnd(t.order.Front().Value.(*trieNode))
+	}
+}
+
+// Throws out the order for a capped trie whose nodes were swapped out
+// from under it, and starts over as though every string had been put in
+// the same order as Keys. If that's more than the cap, the first ones
+// are evicted.
+func (t *Trie) rebuildOrder() {
+	if t.capacity <= 0 {
+		return
+	}
+	t.setCapacity(t.capacity)
+	t.root.walkEnds(nil, func(_ []rune, node *trieNode) bool {
+		t.touch(node)
+		return true
+	})
+	t.evict()
+}
+
+// Returns the string that ends at this node, found by walking up to the
+// root.
+func (t *trieNode) word() string {
+	var runes []rune
+	for node := t; node.parent != nil; node = node.parent {
+		runes = append(runes, node.value)
+	}
+	slices.Reverse(runes)
+	return string(runes)
+}
@@ -0,0 +1,111 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTrieCapped(t *testing.T) {
+	trie := NewTrieCapped(3)
+	if trie.Capacity() != 3 {
+		t.Fatal("Expected Capacity 3; got", trie.Capacity())
+	}
+
+	for _, n := range []string{"apple", "app", "banana"} {
+		trie.Put(n)
+	}
+	// Lookups don't count as puts.
+	trie.Has("apple")
+	trie.Put("cherry")
+	if trie.Has("apple") || trie.Len() != 3 {
+		t.Fatal("Expected apple to be evicted; got", trie.Keys())
+	}
+	if !trie.Has("app") || trie.HasPrefix("appl") {
+		t.Fatal("Expected evicting apple to prune it but keep app; got", trie.Keys())
+	}
+
+	// Putting app again makes it the newest, so banana goes next.
+	trie.Put("app")
+	trie.Put("date")
+	if expected := []string{"app", "cherry", "date"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected", expected, "got", trie.Keys())
+	}
+
+	// Deleted strings don't take up room, or get evicted later.
+	trie.Delete("cherry")
+	trie.Put("elder")
+	if expected := []string{"app", "date", "elder"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected", expected, "got", trie.Keys())
+	}
+	trie.DeletePrefix("d")
+	trie.Put("fig")
+	trie.Put("grape")
+	if expected := []string{"elder", "fig", "grape"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected", expected, "got", trie.Keys())
+	}
+
+	trie.Clear()
+	for _, n := range []string{"a", "b", "c", "d"} {
+		trie.Put(n)
+	}
+	if expected := []string{"b", "c", "d"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected a cleared trie to keep its cap; got", trie.Keys())
+	}
+
+	if NewTrieCapped(0).Capacity() != 0 {
+		t.Fatal("Expected a capacity of 0 to mean no cap")
+	}
+}
+
+func TestTrieCappedCopies(t *testing.T) {
+	trie := NewTrieCapped(3)
+	for _, n := range []string{"c", "", "a"} {
+		trie.Put(n)
+	}
+
+	// Clones keep the order, even for the root.
+	clone := trie.Clone()
+	clone.Put("d")
+	clone.Put("e")
+	if expected := []string{"a", "d", "e"}; !slices.Equal(clone.Keys(), expected) {
+		t.Fatal("Expected the clone to evict in the same order; got", clone.Keys())
+	}
+	if expected := []string{"", "a", "c"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected the original to be left alone; got", trie.Keys())
+	}
+
+	// Decoding has no order to go on, so it uses key order.
+	big := newTrieOf("w", "x", "y", "z")
+	data, _ := big.GobEncode()
+	if err := trie.GobDecode(data); err != nil {
+		t.Fatal("Unexpected error decoding", err)
+	}
+	if expected := []string{"x", "y", "z"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected decoding to evict down to the cap; got", trie.Keys())
+	}
+	trie.Put("a")
+	if expected := []string{"a", "y", "z"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected", expected, "got", trie.Keys())
+	}
+
+	union := trie.Union(newTrieOf("b"))
+	if union.Capacity() != 3 || union.Len() != 3 {
+		t.Fatal("Expected a union to keep the cap; got", union.Keys())
+	}
+}
@@ -0,0 +1,267 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// A node in a TrieMap. Same idea as a trieNode, but a node that ends a
+// key also carries that key's value.
+type trieMapNode[V any] struct {
+	// Created on demand, so leaves don't pay for an empty map.
+	children map[rune]*trieMapNode[V]
+	value    V
+	isEnd    bool
+}
+
+// A map from strings to values of type V, organized as a trie so that
+// everything under a prefix can be found quickly. The zero value is an
+// empty TrieMap, ready to use.
+type TrieMap[V any] struct {
+	root trieMapNode[V]
+	size int
+}
+
+// Creates a new, empty TrieMap for the user.
+//
+// Never returns nil.
+func NewTrieMap[V any]() *TrieMap[V] {
+	return &TrieMap[V]{}
+}
+
+// Returns the node for key, or nil if it isn't there (or utf8 decode
+// error). The empty key maps to the root.
+func (m *TrieMap[V]) searchNode(key string) *trieMapNode[V] {
+	current := &m.root
+	for len(key) != 0 {
+		r, size := utf8.DecodeRuneInString(key)
+		if r == utf8.RuneError && size == 1 {
+			return nil
+		}
+		current = current.children[r]
+		if current == nil {
+			return nil
+		}
+		key = key[size:]
+	}
+	return current
+}
+
+// Associates v with key, replacing any value it already had.
+//
+// Returns a nil error on success. Currently, failure only happens if key
+// has an invalid utf-8 sequence in it, in which case the error is
+// ErrInvalidUTF8 and nothing is stored.
+func (m *TrieMap[V]) Put(key string, v V) error {
+	if !utf8.ValidString(key) {
+		return ErrInvalidUTF8
+	}
+	node := m.insert(key)
+	if !node.isEnd {
+		node.isEnd = true
+		m.size++
+	}
+	node.value = v
+	return nil
+}
+
+// Returns the node for key, adding any nodes that aren't there yet. key
+// must be valid utf8.
+func (m *TrieMap[V]) insert(key string) *trieMapNode[V] {
+	node := &m.root
+	for _, r := range key {
+		child := node.children[r]
+		if child == nil {
+			if node.children == nil {
+				node.children = map[rune]*trieMapNode[V]{}
+			}
+			child = &trieMapNode[V]{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// Sets key's value to whatever fn returns, given its current value, in
+// one trip down the trie. If key isn't in the map yet, fn gets the zero V
+// and false, and key is added.
+//
+// Returns ErrInvalidUTF8 if key has invalid utf8 in it, in which case fn
+// isn't called and nothing is stored.
+func (m *TrieMap[V]) Update(key string, fn func(old V, exists bool) V) error {
+	if !utf8.ValidString(key) {
+		return ErrInvalidUTF8
+	}
+	node := m.insert(key)
+	node.value = fn(node.value, node.isEnd)
+	if !node.isEnd {
+		node.isEnd = true
+		m.size++
+	}
+	return nil
+}
+
+// Returns the value already associated with key and true if there is
+// one. Otherwise, associates v with key and returns v and false. Like
+// sync.Map's LoadOrStore, but it's only one trip down the trie either
+// way. (A TrieMap still isn't safe for concurrent use on its own.)
+//
+// If key has invalid utf8 in it, nothing is stored, and v and false are
+// returned.
+func (m *TrieMap[V]) GetOrPut(key string, v V) (actual V, loaded bool) {
+	if !utf8.ValidString(key) {
+		return v, false
+	}
+	node := m.insert(key)
+	if node.isEnd {
+		return node.value, true
+	}
+	node.isEnd = true
+	node.value = v
+	m.size++
+	return v, false
+}
+
+// Returns the value associated with key. If key isn't in the map (or
+// has invalid utf8 in it), returns the zero V and false.
+func (m *TrieMap[V]) Get(key string) (V, bool) {
+	node := m.searchNode(key)
+	if node == nil || !node.isEnd {
+		var zero V
+		return zero, false
+	}
+	return node.value, true
+}
+
+// Removes key and its value from the map, along with any nodes that only
+// existed to hold it.
+func (m *TrieMap[V]) Delete(key string) {
+	path := []*trieMapNode[V]{&m.root}
+	var runes []rune
+	for len(key) != 0 {
+		r, size := utf8.DecodeRuneInString(key)
+		if r == utf8.RuneError && size == 1 {
+			return
+		}
+		next := path[len(path)-1].children[r]
+		if next == nil {
+			return
+		}
+		path = append(path, next)
+		runes = append(runes, r)
+		key = key[size:]
+	}
+
+	node := path[len(path)-1]
+	if !node.isEnd {
+		return
+	}
+	var zero V
+	node.isEnd = false
+	node.value = zero
+	m.size--
+
+	// Prune every node on the way back up that's no longer needed.
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].isEnd || len(path[i].children) != 0 {
+			break
+		}
+		delete(path[i-1].children, runes[i-1])
+	}
+}
+
+// Returns the number of keys in the map.
+func (m *TrieMap[V]) Len() int {
+	return m.size
+}
+
+// Calls fn on the key and value of every entry at or below this node, in
+// lexicographic order of key. prefix holds the runes on the path from the
+// root down to this node.
+//
+// Returns false if fn asked to stop early.
+func (t *trieMapNode[V]) walk(prefix []rune, fn func([]rune, V) bool) bool {
+	if t.isEnd && !fn(prefix, t.value) {
+		return false
+	}
+	runes := make([]rune, 0, len(t.children))
+	for r := range t.children {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	for _, r := range runes {
+		if !t.children[r].walk(append(prefix, r), fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the values of every key that starts with prefix (including
+// prefix itself), ordered by key the same way Trie.Keys is.
+//
+// Never returns nil; if no key starts with prefix, an empty slice is
+// returned.
+func (m *TrieMap[V]) PrefixValues(prefix string) []V {
+	out := []V{}
+	node := m.searchNode(prefix)
+	if node == nil {
+		return out
+	}
+	node.walk([]rune(prefix), func(_ []rune, v V) bool {
+		out = append(out, v)
+		return true
+	})
+	return out
+}
+
+// Returns every key in the map along with its value, ordered by key the
+// same way Trie.Keys is.
+//
+// Never returns nil; an empty map gives an empty slice.
+func (m *TrieMap[V]) Entries() []struct {
+	Key   string
+	Value V
+} {
+	out := []struct {
+		Key   string
+		Value V
+	}{}
+	m.root.walk(nil, func(key []rune, v V) bool {
+		out = append(out, struct {
+			Key   string
+			Value V
+		}{string(key), v})
+		return true
+	})
+	return out
+}
+
+// Returns a new map with the same keys and values as m.
+//
+// Never returns nil; an empty TrieMap gives an empty map.
+func (m *TrieMap[V]) ToMap() map[string]V {
+	out := make(map[string]V, m.size)
+	m.root.walk(nil, func(key []rune, v V) bool {
+		out[string(key)] = v
+		return true
+	})
+	return out
+}
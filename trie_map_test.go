@@ -0,0 +1,215 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestTrieMapPutGetDelete(t *testing.T) {
+	m := NewTrieMap[int]()
+	if _, ok := m.Get("alice"); ok {
+		t.Fatal("Expected an empty map to have nothing")
+	}
+
+	users := map[string]int{"alice": 1, "alicia": 2, "al": 3, "bob": 4, "": 5}
+	for k, v := range users {
+		if err := m.Put(k, v); err != nil {
+			t.Fatal("Unexpected error putting", k, err)
+		}
+	}
+	for k, v := range users {
+		if got, ok := m.Get(k); !ok || got != v {
+			t.Fatalf("Get(%q): expected %d, got %d, %v", k, v, got, ok)
+		}
+	}
+	if _, ok := m.Get("ali"); ok {
+		t.Fatal("Expected a prefix to not be a key")
+	}
+	if m.Len() != len(users) {
+		t.Fatal("Expected Len", len(users), "got", m.Len())
+	}
+
+	m.Put("alice", 10)
+	if got, _ := m.Get("alice"); got != 10 || m.Len() != len(users) {
+		t.Fatal("Expected Put to replace alice's value; got", got)
+	}
+
+	m.Delete("al")
+	if _, ok := m.Get("al"); ok {
+		t.Fatal("Expected al to be gone")
+	}
+	if got, ok := m.Get("alice"); !ok || got != 10 {
+		t.Fatal("Expected deleting al to leave alice alone")
+	}
+
+	m.Delete("alice")
+	m.Delete("alicia")
+	if m.root.children['a'] != nil {
+		t.Fatal("Expected deleting every a-key to prune the a branch")
+	}
+	if m.Len() != 2 {
+		t.Fatal("Expected Len 2; got", m.Len())
+	}
+
+	if err := m.Put("bad\xff", 1); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+}
+
+func TestTrieMapZeroValue(t *testing.T) {
+	var m TrieMap[int]
+	if _, ok := m.Get("a"); ok || m.Len() != 0 || len(m.PrefixValues("")) != 0 {
+		t.Fatal("Expected a zero TrieMap to be empty")
+	}
+	m.Delete("a")
+	if err := m.Put("ab", 1); err != nil {
+		t.Fatal("Unexpected error putting into a zero TrieMap:", err)
+	}
+	m.Put("", 2)
+	if got, ok := m.Get("ab"); !ok || got != 1 || m.Len() != 2 {
+		t.Fatal("Expected a zero TrieMap to be usable; got", m.ToMap())
+	}
+}
+
+func TestTrieMapPrefixValues(t *testing.T) {
+	m := NewTrieMap[string]()
+	m.Put("car", "vehicle")
+	m.Put("cart", "trolley")
+	m.Put("carbon", "element")
+	m.Put("dog", "animal")
+
+	expected := []string{"vehicle", "element", "trolley"}
+	if values := m.PrefixValues("car"); !slices.Equal(values, expected) {
+		t.Fatal("Expected", expected, "got", values)
+	}
+	if values := m.PrefixValues("x"); values == nil || len(values) != 0 {
+		t.Fatal("Expected empty, non-nil values; got", values)
+	}
+	if values := m.PrefixValues(""); len(values) != 4 {
+		t.Fatal("Expected every value for the empty prefix; got", values)
+	}
+}
+
+func TestTrieMapEntries(t *testing.T) {
+	m := NewTrieMap[int]()
+	if entries := m.Entries(); entries == nil || len(entries) != 0 {
+		t.Fatal("Expected empty, non-nil entries; got", entries)
+	}
+
+	m.Put("b", 2)
+	m.Put("", 0)
+	m.Put("ab", 12)
+	m.Put("a", 1)
+	m.Put("é", 5)
+
+	type entry = struct {
+		Key   string
+		Value int
+	}
+	expected := []entry{{"", 0}, {"a", 1}, {"ab", 12}, {"b", 2}, {"é", 5}}
+	if entries := m.Entries(); !slices.Equal(entries, expected) {
+		t.Fatal("Expected", expected, "got", entries)
+	}
+}
+
+func TestTrieMapToMap(t *testing.T) {
+	m := NewTrieMap[int]()
+	if got := m.ToMap(); got == nil || len(got) != 0 {
+		t.Fatal("Expected an empty, non-nil map; got", got)
+	}
+
+	m.Put("b", 2)
+	m.Put("", 0)
+	m.Put("ab", 12)
+	got := m.ToMap()
+	if !maps.Equal(got, map[string]int{"b": 2, "": 0, "ab": 12}) {
+		t.Fatal("Unexpected map:", got)
+	}
+
+	// The map is a copy.
+	got["z"] = 26
+	if _, ok := m.Get("z"); ok {
+		t.Fatal("Expected changing the map to leave the TrieMap alone")
+	}
+}
+
+func TestTrieMapGetOrPut(t *testing.T) {
+	m := NewTrieMap[int]()
+	m.Put("car", 1)
+
+	if v, loaded := m.GetOrPut("car", 2); v != 1 || !loaded {
+		t.Fatal("Expected to load car's value 1; got", v, loaded)
+	}
+	// Only a prefix so far, so this is a new key.
+	if v, loaded := m.GetOrPut("ca", 3); v != 3 || loaded {
+		t.Fatal("Expected to store 3 for ca; got", v, loaded)
+	}
+	if v, loaded := m.GetOrPut("ca", 4); v != 3 || !loaded {
+		t.Fatal("Expected to load ca's value 3; got", v, loaded)
+	}
+	if v, ok := m.Get("ca"); v != 3 || !ok || m.Len() != 2 {
+		t.Fatal("Expected GetOrPut to store ca; got", v, ok, m.Len())
+	}
+
+	if v, loaded := m.GetOrPut("bad\xff", 5); v != 5 || loaded || m.Len() != 2 {
+		t.Fatal("Expected nothing to be stored for a bad key; got", v, loaded, m.Len())
+	}
+}
+
+func TestTrieMapUpdate(t *testing.T) {
+	m := NewTrieMap[int]()
+	increment := func(old int, exists bool) int {
+		return old + 1
+	}
+	for _, w := range []string{"go", "go", "gopher", "go"} {
+		if err := m.Update(w, increment); err != nil {
+			t.Fatal("Unexpected error updating", w, err)
+		}
+	}
+	if v, _ := m.Get("go"); v != 3 {
+		t.Fatal("Expected go to be counted 3 times; got", v)
+	}
+	if v, _ := m.Get("gopher"); v != 1 || m.Len() != 2 {
+		t.Fatal("Expected gopher to be counted once; got", v, m.Len())
+	}
+
+	var sawExists []bool
+	m.Update("new", func(old int, exists bool) int {
+		sawExists = append(sawExists, exists)
+		return 10
+	})
+	m.Update("new", func(old int, exists bool) int {
+		sawExists = append(sawExists, exists)
+		return old * 2
+	})
+	if v, _ := m.Get("new"); v != 20 || !slices.Equal(sawExists, []bool{false, true}) {
+		t.Fatal("Expected new to be 20 after seeing false then true; got", v, sawExists)
+	}
+
+	called := false
+	err := m.Update("bad\xff", func(int, bool) int {
+		called = true
+		return 0
+	})
+	if !errors.Is(err, ErrInvalidUTF8) || called || m.Len() != 3 {
+		t.Fatal("Expected ErrInvalidUTF8 without calling fn; got", err, called, m.Len())
+	}
+}
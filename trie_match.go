@@ -0,0 +1,234 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// Appends to out every string below this node that matches pattern,
+// where '?' matches any single rune. prefix holds the runes on the path
+// from the root down to this node.
+func (t *trieNode) matchWildcard(pattern, prefix []rune, out []string) []string {
+	if len(pattern) == 0 {
+		if t.isEnd {
+			out = append(out, string(prefix))
+		}
+		return out
+	}
+
+	r := pattern[0]
+	if r != '?' {
+		if child := t.child(r); child != nil {
+			out = child.matchWildcard(pattern[1:], append(prefix, r), out)
+		}
+		return out
+	}
+	for c, child := range t.sortedChildren() {
+		out = child.matchWildcard(pattern[1:], append(prefix, c), out)
+	}
+	return out
+}
+
+// Returns every string stored in the trie that matches pattern, where
+// '?' matches exactly one rune and every other rune matches itself. So
+// "c?t" matches "cat" and "cut", but not "ct" or "cart". Results are
+// ordered lexicographically by rune value.
+//
+// Never returns nil; if nothing matches (or pattern has invalid utf8 in
+// it), an empty slice is returned.
+func (t *Trie) MatchWildcard(pattern string) []string {
+	pattern = t.canonical(pattern)
+	if !utf8.ValidString(pattern) {
+		return []string{}
+	}
+	return t.root.matchWildcard([]rune(pattern), nil, []string{})
+}
+
+// Squashes runs of consecutive '*'s in pattern into one, since they
+// match the same things.
+func collapseStars(pattern []rune) []rune {
+	out := pattern[:0]
+	for i, r := range pattern {
+		if r == '*' && i > 0 && pattern[i-1] == '*' {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// Marks every position that's reachable from a marked one by letting a
+// '*' match nothing.
+func closeGlobStates(pattern []rune, states []bool) {
+	for i, r := range pattern {
+		if states[i] && r == '*' {
+			states[i+1] = true
+		}
+	}
+}
+
+// Returns the set of pattern positions reachable from states by
+// consuming r, or nil if there aren't any.
+func stepGlobStates(pattern []rune, states []bool, r rune) []bool {
+	var next []bool
+	for i, p := range pattern {
+		if !states[i] {
+			continue
+		}
+		var to int
+		switch {
+		case p == '*':
+			to = i
+		case p == '?' || p == r:
+			to = i + 1
+		default:
+			continue
+		}
+		if next == nil {
+			next = make([]bool, len(states))
+		}
+		next[to] = true
+	}
+	if next != nil {
+		closeGlobStates(pattern, next)
+	}
+	return next
+}
+
+// Appends to out every string below this node that matches pattern.
+// states holds every position in pattern that the runes on the path to
+// this node can leave us at; rather than backtracking, every way a '*'
+// could be matched is followed at once, so no string is found twice.
+func (t *trieNode) matchGlob(pattern []rune, states []bool, prefix []rune, out []string) []string {
+	if t.isEnd && states[len(pattern)] {
+		out = append(out, string(prefix))
+	}
+
+	// If no wildcard is in play, only the literal runes we're waiting
+	// on can lead anywhere.
+	var runes []rune
+	for i, p := range pattern {
+		if !states[i] {
+			continue
+		}
+		if p == '*' || p == '?' {
+			runes = t.sortedRunes()
+			break
+		}
+		runes = append(runes, p)
+	}
+	if len(runes) > 1 && !slices.IsSorted(runes) {
+		slices.Sort(runes)
+	}
+	runes = slices.Compact(runes)
+
+	for _, r := range runes {
+		child := t.child(r)
+		if child == nil {
+			continue
+		}
+		if next := stepGlobStates(pattern, states, r); next != nil {
+			out = child.matchGlob(pattern, next, append(prefix, r), out)
+		}
+	}
+	return out
+}
+
+// Returns every string stored in the trie that matches pattern, where
+// '*' matches any run of zero or more runes, '?' matches exactly one
+// rune, and every other rune matches itself. So "a*z" matches "az",
+// "abz" and "abcz". A pattern without wildcards only matches itself, the
+// same as Has. Results are ordered lexicographically by rune value.
+//
+// Never returns nil; if nothing matches (or pattern has invalid utf8 in
+// it), an empty slice is returned.
+func (t *Trie) MatchGlob(pattern string) []string {
+	pattern = t.canonical(pattern)
+	if !utf8.ValidString(pattern) {
+		return []string{}
+	}
+
+	runes := collapseStars([]rune(pattern))
+	states := make([]bool, len(runes)+1)
+	states[0] = true
+	closeGlobStates(runes, states)
+	return t.root.matchGlob(runes, states, nil, []string{})
+}
+
+// Appends to out every string below this node within maxDist edits of
+// query. prevRow is the row of the Levenshtein distance table for the
+// path down to this node: prevRow[i] is the distance between that path
+// and the first i runes of query.
+func (t *trieNode) fuzzySearch(query []rune, prevRow []int, maxDist int, prefix []rune, out []string) []string {
+	for r, child := range t.sortedChildren() {
+		row := make([]int, len(query)+1)
+		row[0] = prevRow[0] + 1
+		best := row[0]
+		for i := 1; i <= len(query); i++ {
+			cost := 1
+			if query[i-1] == r {
+				cost = 0
+			}
+			row[i] = min(row[i-1]+1, prevRow[i]+1, prevRow[i-1]+cost)
+			best = min(best, row[i])
+		}
+
+		// Rows never shrink as the path gets longer, so once every entry
+		// is over maxDist, nothing further down can match.
+		if best > maxDist {
+			continue
+		}
+
+		path := append(prefix, r)
+		if child.isEnd && row[len(query)] <= maxDist {
+			out = append(out, string(path))
+		}
+		out = child.fuzzySearch(query, row, maxDist, path, out)
+	}
+	return out
+}
+
+// Returns every string stored in the trie that's within maxDist edits
+// (insertions, deletions or substitutions of a single rune) of query;
+// that is, at most maxDist Levenshtein distance away, measured in runes.
+// Results are ordered lexicographically by rune value.
+//
+// Branches of the trie are skipped as soon as they can't get within
+// maxDist, so this is much cheaper than checking every key.
+//
+// Never returns nil; if nothing matches (or query has invalid utf8 in
+// it, or maxDist is negative), an empty slice is returned.
+func (t *Trie) FuzzySearch(query string, maxDist int) []string {
+	query = t.canonical(query)
+	if maxDist < 0 || !utf8.ValidString(query) {
+		return []string{}
+	}
+
+	runes := []rune(query)
+	row := make([]int, len(runes)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	out := []string{}
+	if t.root.isEnd && len(runes) <= maxDist {
+		out = append(out, "")
+	}
+	return t.root.fuzzySearch(runes, row, maxDist, nil, out)
+}
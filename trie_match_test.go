@@ -0,0 +1,148 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestTrieMatchWildcard(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"cat", "cot", "cut", "ct", "cart", "coat", "dog", "cé", "c"} {
+		trie.Put(n)
+	}
+
+	cases := []struct {
+		pattern string
+		matches []string
+	}{
+		{"c?t", []string{"cat", "cot", "cut"}},
+		{"c??t", []string{"cart", "coat"}},
+		{"???", []string{"cat", "cot", "cut", "dog"}},
+		{"c?", []string{"ct", "cé"}},
+		{"?", []string{"c"}},
+		{"cat", []string{"cat"}},
+		{"ca", []string{}},
+		{"c?t?", []string{}},
+		{"????????", []string{}},
+		{"", []string{}},
+		{"c?\xff", []string{}},
+	}
+	for _, c := range cases {
+		matches := trie.MatchWildcard(c.pattern)
+		if matches == nil || !slices.Equal(matches, c.matches) {
+			t.Fatalf("MatchWildcard(%q): expected %v, got %v", c.pattern, c.matches, matches)
+		}
+	}
+}
+
+func TestTrieMatchGlob(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"az", "abz", "abcz", "azz", "a", "ab", "b", "bz", "zaz", "aéz"} {
+		trie.Put(n)
+	}
+
+	cases := []struct {
+		pattern string
+		matches []string
+	}{
+		{"a*z", []string{"abcz", "abz", "az", "azz", "aéz"}},
+		{"a**z", []string{"abcz", "abz", "az", "azz", "aéz"}},
+		{"*z", []string{"abcz", "abz", "az", "azz", "aéz", "bz", "zaz"}},
+		{"a*", []string{"a", "ab", "abcz", "abz", "az", "azz", "aéz"}},
+		{"*", []string{"a", "ab", "abcz", "abz", "az", "azz", "aéz", "b", "bz", "zaz"}},
+		{"a?*z", []string{"abcz", "abz", "azz", "aéz"}},
+		{"*a*", []string{"a", "ab", "abcz", "abz", "az", "azz", "aéz", "zaz"}},
+		{"a*b*", []string{"ab", "abcz", "abz"}},
+		{"*z*z", []string{"azz", "zaz"}},
+		{"ab", []string{"ab"}},
+		{"abc", []string{}},
+		{"", []string{}},
+		{"c*", []string{}},
+		{"a*\xff", []string{}},
+	}
+	for _, c := range cases {
+		matches := trie.MatchGlob(c.pattern)
+		if matches == nil || !slices.Equal(matches, c.matches) {
+			t.Fatalf("MatchGlob(%q): expected %v, got %v", c.pattern, c.matches, matches)
+		}
+	}
+
+	trie.Put("")
+	if matches := trie.MatchGlob("*"); matches[0] != "" {
+		t.Fatal("Expected * to match the empty string; got", matches)
+	}
+	if matches := trie.MatchGlob(""); !slices.Equal(matches, []string{""}) {
+		t.Fatal("Expected an empty pattern to match the empty string; got", matches)
+	}
+}
+
+func TestTrieFuzzySearch(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"the", "then", "they", "them", "tea", "hte", "ten", "a", "тhe", "thé"} {
+		trie.Put(n)
+	}
+
+	cases := []struct {
+		query   string
+		maxDist int
+		matches []string
+	}{
+		{"the", 0, []string{"the"}},
+		{"the", 1, []string{"the", "them", "then", "they", "thé", "тhe"}},
+		{"the", 2, []string{"hte", "tea", "ten", "the", "them", "then", "they", "thé", "тhe"}},
+		// Distance is in runes, so swapping é for e is a single edit even
+		// though it's two bytes.
+		{"thé", 1, []string{"the", "thé"}},
+		{"xyz", 1, []string{}},
+		{"", 1, []string{"a"}},
+		{"the", -1, []string{}},
+		{"th\xff", 1, []string{}},
+	}
+	for _, c := range cases {
+		matches := trie.FuzzySearch(c.query, c.maxDist)
+		if matches == nil || !slices.Equal(matches, c.matches) {
+			t.Fatalf("FuzzySearch(%q, %d): expected %v, got %v", c.query, c.maxDist, c.matches, matches)
+		}
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+func BenchmarkTrieFuzzySearch(b *testing.B) {
+	const numStrings = 100000
+	const strLen = 10
+
+	rand.Seed(0) // Arbitrary seed
+
+	trie := NewTrie()
+	buf := make([]rune, strLen)
+	for i := 0; i < numStrings; i++ {
+		for x := range buf {
+			buf[x] = rune(rand.Int31n(26) + 'a')
+		}
+		trie.Put(string(buf))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.FuzzySearch("abcdefghij", 2)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+// The kind of change an OnChange callback is told about.
+type Op int
+
+const (
+	// A string was added to the trie.
+	Insert Op = iota
+	// A string was removed from the trie.
+	Delete
+)
+
+func (op Op) String() string {
+	switch op {
+	case Insert:
+		return "Insert"
+	case Delete:
+		return "Delete"
+	}
+	return "Op(?)"
+}
+
+// Registers fn to be called whenever a string is actually added to or
+// removed from the trie, by Put, Delete, or any other method that changes
+// what's in it. Putting a string that's already there, or deleting one
+// that isn't (or is only a prefix of others), doesn't call fn. word is in
+// the form it's stored in (see NewTrieFold).
+//
+// Callbacks run after the change they report is done, in the order they
+// were registered, on the goroutine that made the change. A Put that
+// evicts an old string from a capped trie reports the Delete before the
+// Insert. Methods that remove many strings at once (DeletePrefix, Clear,
+// ...) report one Delete per string, and UnmarshalBinary (and so
+// GobDecode) reports every old string as deleted and every new one as
+// inserted. fn must not change the trie.
+//
+// Callbacks belong to this trie only; Clone, SubTrie and the like don't
+// copy them.
+func (t *Trie) OnChange(fn func(op Op, word string)) {
+	t.observers = append(t.observers, fn)
+}
+
+// Calls every registered callback for one change.
+func (t *Trie) notify(op Op, word string) {
+	for _, fn := range t.observers {
+		fn(op, word)
+	}
+}
+
+// Calls every registered callback for each of words.
+func (t *Trie) notifyAll(op Op, words []string) {
+	for _, w := range words {
+		t.notify(op, w)
+	}
+}
+
+// Returns every string at or below node, if anyone's going to be told
+// about them; nil otherwise.
+func (t *Trie) wordsToNotify(node *trieNode) []string {
+	if len(t.observers) == 0 {
+		return nil
+	}
+	return node.collect([]rune(node.word()), nil)
+}
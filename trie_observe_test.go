@@ -0,0 +1,122 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// Returns a trie that records every change it reports, as "+word" or
+// "-word", into *log.
+func newObservedTrie(t *Trie, log *[]string) *Trie {
+	t.OnChange(func(op Op, word string) {
+		if op == Insert {
+			*log = append(*log, "+"+word)
+		} else {
+			*log = append(*log, "-"+word)
+		}
+	})
+	return t
+}
+
+func TestTrieOnChange(t *testing.T) {
+	var log []string
+	trie := newObservedTrie(NewTrieFold(), &log)
+	expect := func(want ...string) {
+		t.Helper()
+		if !slices.Equal(log, want) {
+			t.Fatalf("Expected changes %q; got %q", want, log)
+		}
+		log = nil
+	}
+
+	trie.Put("Apple")
+	trie.Put("apple")
+	trie.PutBytes([]byte("app"))
+	trie.Add("apricot")
+	trie.Put("bad\xff")
+	expect("+apple", "+app", "+apricot")
+
+	if trie.Len() != 3 {
+		t.Fatal("Expected 3 strings; got", trie.Len())
+	}
+
+	trie.Delete("ap")
+	trie.Delete("missing")
+	trie.Delete("APP")
+	expect("-app")
+
+	trie.Put("banana")
+	trie.Put("bandana")
+	trie.DeletePrefix("ban")
+	expect("+banana", "+bandana", "-banana", "-bandana")
+
+	trie.Clear()
+	expect("-apple", "-apricot")
+
+	// Every observer hears about every change, in order.
+	var second []string
+	trie.OnChange(func(op Op, word string) {
+		second = append(second, fmt.Sprint(op, " ", word))
+	})
+	trie.Put("x")
+	expect("+x")
+	if !slices.Equal(second, []string{"Insert x"}) {
+		t.Fatal("Expected the second observer to see Insert x; got", second)
+	}
+
+	// The trie has already changed by the time an observer's called.
+	trie.OnChange(func(op Op, word string) {
+		if trie.Has(word) != (op == Insert) {
+			t.Fatal("Expected", op, word, "to be done before notifying")
+		}
+	})
+	trie.Put("y")
+	trie.Delete("y")
+	expect("+y", "-y")
+}
+
+func TestTrieOnChangeEvictAndDecode(t *testing.T) {
+	var log []string
+	trie := newObservedTrie(NewTrieCapped(2), &log)
+	trie.Put("a")
+	trie.Put("b")
+	trie.Put("c")
+	if !slices.Equal(log, []string{"+a", "+b", "-a", "+c"}) {
+		t.Fatal("Expected the eviction before the insert; got", log)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(newTrieOf("d")); err != nil {
+		t.Fatal(err)
+	}
+	log = nil
+	if err := gob.NewDecoder(&buf).Decode(trie); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(log, []string{"-b", "-c", "+d"}) {
+		t.Fatal("Expected decoding to replace everything; got", log)
+	}
+
+	if clone := trie.Clone(); clone.Put("e") != nil || len(log) != 3 {
+		t.Fatal("Expected a clone not to share observers; got", log)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+)
+
+// Splits s into strings from the trie greedily: the longest string in
+// the trie that s starts with comes first, then the longest that the rest
+// starts with, and so on. The empty string is never used.
+//
+// Returns the pieces and true if that uses up all of s. If it gets stuck,
+// returns the pieces found up to that point and false. Being greedy, it
+// can get stuck even when there's a way to split s; see WordBreak.
+//
+// Never returns nil.
+func (t *Trie) Tokenize(s string) ([]string, bool) {
+	s = t.canonical(s)
+	tokens := []string{}
+	for len(s) != 0 {
+		longest := 0
+		t.eachPrefixEnd(s, func(end int) bool {
+			longest = end
+			return true
+		})
+		if longest == 0 {
+			return tokens, false
+		}
+		tokens = append(tokens, s[:longest])
+		s = s[longest:]
+	}
+	return tokens, true
+}
+
+// Splits s into strings from the trie, if there's any way to. Unlike
+// Tokenize, this doesn't get stuck on a bad early choice: with "ab",
+// "abc" and "cd" in the trie, "abcd" splits into "ab", "cd".
+//
+// Works forward through s, one reachable position at a time, and only
+// tries the pieces the trie has starting there, so it takes about
+// O(len(s) * longest string in the trie) steps.
+//
+// Returns the pieces (as stored, so folded if the trie folds) and true,
+// or an empty slice and false if s can't be split. The empty string is
+// never used as a piece. Never returns nil.
+func (t *Trie) WordBreak(s string) ([]string, bool) {
+	s = t.canonical(s)
+
+	// from[i] is where the piece ending at byte i started, on some way of
+	// splitting s[:i]; -1 if there's no such way.
+	from := make([]int, len(s)+1)
+	for i := range from {
+		from[i] = -1
+	}
+	from[0] = 0
+	for i := 0; i < len(s) && from[len(s)] < 0; i++ {
+		if from[i] < 0 {
+			continue
+		}
+		t.eachPrefixEnd(s[i:], func(end int) bool {
+			if from[i+end] < 0 {
+				from[i+end] = i
+			}
+			return true
+		})
+	}
+	if from[len(s)] < 0 {
+		return []string{}, false
+	}
+
+	tokens := []string{}
+	for end := len(s); end > 0; end = from[end] {
+		tokens = append(tokens, s[from[end]:end])
+	}
+	slices.Reverse(tokens)
+	return tokens, true
+}
+
+// Returns every way to split s into strings from the trie, like
+// WordBreak but without stopping at the first. Splits are ordered by
+// their first piece, shortest first, then by their second, and so on. The
+// empty string is never used as a piece, and s == "" has exactly one
+// split, with no pieces in it.
+//
+// Each position in s is only worked out once, and splits of the rest of
+// s from there are shared by everything that reaches it, so a dead end
+// is never explored twice. There can still be exponentially many splits
+// to return, though.
+//
+// Never returns nil; if s can't be split, returns an empty slice.
+func (t *Trie) AllSegmentations(s string) [][]string {
+	s = t.canonical(s)
+
+	// splits[i] holds every split of s[i:], once it's been worked out.
+	splits := make([][][]string, len(s)+1)
+	splits[len(s)] = [][]string{{}}
+	var from func(i int) [][]string
+	from = func(i int) [][]string {
+		if splits[i] != nil {
+			return splits[i]
+		}
+		found := [][]string{}
+		t.eachPrefixEnd(s[i:], func(end int) bool {
+			for _, rest := range from(i + end) {
+				found = append(found, append([]string{s[i : i+end]}, rest...))
+			}
+			return true
+		})
+		splits[i] = found
+		return found
+	}
+	return from(0)
+}
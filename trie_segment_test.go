@@ -0,0 +1,151 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrieTokenize(t *testing.T) {
+	trie := newTrieOf("the", "quick", "brown", "fox", "th", "qu", "café", "")
+
+	cases := []struct {
+		s      string
+		tokens []string
+		ok     bool
+	}{
+		{"thequickbrownfox", []string{"the", "quick", "brown", "fox"}, true},
+		{"", []string{}, true},
+		{"thecafé", []string{"the", "café"}, true},
+		{"thequickbrowndog", []string{"the", "quick", "brown"}, false},
+		{"dog", []string{}, false},
+		{"the\xfffox", []string{"the"}, false},
+	}
+	for _, c := range cases {
+		tokens, ok := trie.Tokenize(c.s)
+		if !slices.Equal(tokens, c.tokens) || ok != c.ok || tokens == nil {
+			t.Fatalf("Tokenize(%q): expected (%q, %v), got (%q, %v)", c.s, c.tokens, c.ok, tokens, ok)
+		}
+	}
+
+	fold := NewTrieFold()
+	fold.Put("Hello")
+	fold.Put("World")
+	if tokens, ok := fold.Tokenize("HelloWORLD"); !ok || !slices.Equal(tokens, []string{"hello", "world"}) {
+		t.Fatal("Expected folded tokens; got", tokens, ok)
+	}
+}
+
+func TestTrieWordBreak(t *testing.T) {
+	trie := newTrieOf("a", "ab", "abc", "cd", "the", "them", "mess", "message", "sage")
+
+	cases := []struct {
+		s      string
+		tokens []string
+		ok     bool
+	}{
+		// Greedy takes "abc" and then gets stuck on "d".
+		{"abcd", []string{"ab", "cd"}, true},
+		// Greedy takes "them" and then gets stuck on "essage".
+		{"themessage", []string{"the", "message"}, true},
+		{"", []string{}, true},
+		{"aaaa", []string{"a", "a", "a", "a"}, true},
+		{"abca", []string{"abc", "a"}, true},
+		{"abdc", []string{}, false},
+		{"the\xffmess", []string{}, false},
+	}
+	for _, c := range cases {
+		if greedy, ok := trie.Tokenize(c.s); ok && !c.ok {
+			t.Fatalf("Tokenize(%q) unexpectedly found %q", c.s, greedy)
+		}
+		tokens, ok := trie.WordBreak(c.s)
+		if !slices.Equal(tokens, c.tokens) || ok != c.ok || tokens == nil {
+			t.Fatalf("WordBreak(%q): expected (%q, %v), got (%q, %v)", c.s, c.tokens, c.ok, tokens, ok)
+		}
+	}
+	if _, ok := trie.Tokenize("abcd"); ok {
+		t.Fatal("Expected greedy tokenizing to fail on abcd")
+	}
+}
+
+func TestTrieAllSegmentations(t *testing.T) {
+	trie := newTrieOf("a", "aa", "aaa", "b", "ab", "")
+
+	cases := []struct {
+		s      string
+		splits [][]string
+	}{
+		{"aab", [][]string{
+			{"a", "a", "b"},
+			{"a", "ab"},
+			{"aa", "b"},
+		}},
+		{"aaa", [][]string{
+			{"a", "a", "a"},
+			{"a", "aa"},
+			{"aa", "a"},
+			{"aaa"},
+		}},
+		{"", [][]string{{}}},
+		{"abc", [][]string{}},
+		{"a\xffb", [][]string{}},
+	}
+	for _, c := range cases {
+		splits := trie.AllSegmentations(c.s)
+		if splits == nil || !slices.EqualFunc(splits, c.splits, slices.Equal) {
+			t.Fatalf("AllSegmentations(%q): expected %q, got %q", c.s, c.splits, splits)
+		}
+	}
+
+	// Every split is a real one, and WordBreak finds one of them.
+	s := "aabaaab"
+	splits := trie.AllSegmentations(s)
+	for _, split := range splits {
+		joined := ""
+		for _, piece := range split {
+			if !trie.Has(piece) || piece == "" {
+				t.Fatal("Unexpected piece", piece, "in", split)
+			}
+			joined += piece
+		}
+		if joined != s {
+			t.Fatal("Expected", split, "to join into", s)
+		}
+	}
+	if split, ok := trie.WordBreak(s); !ok || !slices.ContainsFunc(splits, func(x []string) bool { return slices.Equal(x, split) }) {
+		t.Fatal("Expected WordBreak's split to be among", splits, "got", split)
+	}
+}
+
+// --------- Here be benchmarks ------------
+
+// All those a's give an exponential number of ways to get to the end,
+// but the b kills every one of them. Without sharing work on the rest of
+// the string, this would never finish.
+func BenchmarkTrieAllSegmentationsDeadEnd(b *testing.B) {
+	trie := newTrieOf("a", "aa", "aaa", "aaaa")
+	s := strings.Repeat("a", 200) + "b"
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if splits := trie.AllSegmentations(s); len(splits) != 0 {
+			b.Fatal("Expected no splits; got", len(splits))
+		}
+	}
+}
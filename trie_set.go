@@ -0,0 +1,142 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+// Returns a new trie holding every string that's in either t or other.
+// Neither operand is changed.
+//
+// The result has the same options (folding, etc.) as t, and other's
+// strings are put into it the same way Put would; any that t couldn't
+// hold (e.g. non-ASCII strings when t was made by NewTrieASCII) are left
+// out.
+//
+// Never returns nil.
+func (t *Trie) Union(other *Trie) *Trie {
+	union := t.Clone()
+	other.Walk(func(word string) bool {
+		union.Put(word)
+		return true
+	})
+	return union
+}
+
+// Returns a new trie holding only the strings that are in both t and
+// other. Neither operand is changed. The result has the same options
+// (folding, etc.) as t.
+//
+// Never returns nil; if nothing is shared, the result is an empty trie.
+func (t *Trie) Intersection(other *Trie) *Trie {
+	intersection := t.emptyCopy()
+	small, big := t, other
+	if big.Len() < small.Len() {
+		small, big = big, small
+	}
+	small.Walk(func(word string) bool {
+		if big.Has(word) {
+			intersection.Put(word)
+		}
+		return true
+	})
+	return intersection
+}
+
+// Returns a new trie holding the strings in t that aren't in other.
+// Only whole strings in other count; a string that's merely a prefix of
+// something in other (or has something in other as its prefix) is kept.
+// Neither operand is changed. The result has the same options (folding,
+// etc.) as t.
+//
+// Never returns nil.
+func (t *Trie) Difference(other *Trie) *Trie {
+	difference := t.emptyCopy()
+	t.Walk(func(word string) bool {
+		if !other.Has(word) {
+			difference.Put(word)
+		}
+		return true
+	})
+	return difference
+}
+
+// Reports whether this node and other have the same runes below them,
+// with strings ending in the same places.
+func (t *trieNode) equal(other *trieNode) bool {
+	if t.isEnd != other.isEnd || t.numChildren() != other.numChildren() {
+		return false
+	}
+	for r, child := range t.children() {
+		otherChild := other.child(r)
+		if otherChild == nil || !child.equal(otherChild) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether t and other hold exactly the same strings.
+//
+// This compares the node structure of the two tries directly (how each
+// node keeps its children doesn't matter, just which runes they're
+// for). Since a trie never keeps nodes that don't lead to a string, two
+// tries with the same strings always have the same structure, so this is
+// the same as comparing Keys; but it would also catch a trie with a
+// leftover empty branch. Options like folding aren't compared.
+func (t *Trie) Equal(other *Trie) bool {
+	return t.size == other.size && t.root.equal(&other.root)
+}
+
+// Compares t with other, a newer version of it: added holds the strings
+// in other that aren't in t, and removed holds the ones in t that aren't
+// in other, both in the same order as Keys. Neither trie is changed.
+//
+// The two tries are walked side by side, so a branch that's only in one
+// of them is collected without looking anything up in the other.
+//
+// Never returns nil slices; if the tries hold the same strings, both are
+// empty.
+func (t *Trie) Diff(other *Trie) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	t.root.diff(&other.root, nil, &added, &removed)
+	return added, removed
+}
+
+// Does the work of Diff for this node and the one at the same place in
+// the other trie. prefix holds the runes on the path from the root down
+// to both of them.
+func (t *trieNode) diff(other *trieNode, prefix []rune, added, removed *[]string) {
+	if t.isEnd && !other.isEnd {
+		*removed = append(*removed, string(prefix))
+	} else if other.isEnd && !t.isEnd {
+		*added = append(*added, string(prefix))
+	}
+
+	mine, theirs := t.sortedRunes(), other.sortedRunes()
+	for len(mine) != 0 || len(theirs) != 0 {
+		switch {
+		case len(theirs) == 0 || len(mine) != 0 && mine[0] < theirs[0]:
+			*removed = t.child(mine[0]).collect(append(prefix, mine[0]), *removed)
+			mine = mine[1:]
+		case len(mine) == 0 || theirs[0] < mine[0]:
+			*added = other.child(theirs[0]).collect(append(prefix, theirs[0]), *added)
+			theirs = theirs[1:]
+		default:
+			r := mine[0]
+			t.child(r).diff(other.child(r), append(prefix, r), added, removed)
+			mine, theirs = mine[1:], theirs[1:]
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"testing"
+)
+
+func newTrieOf(words ...string) *Trie {
+	trie := NewTrie()
+	for _, w := range words {
+		trie.Put(w)
+	}
+	return trie
+}
+
+func TestTrieUnion(t *testing.T) {
+	a := newTrieOf("apple", "banana", "app")
+	b := newTrieOf("banana", "cherry", "appl")
+
+	union := a.Union(b)
+	expected := []string{"app", "appl", "apple", "banana", "cherry"}
+	if keys := union.Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+	if union.Len() != len(expected) {
+		t.Fatal("Expected Len", len(expected), "got", union.Len())
+	}
+
+	if keys := a.Keys(); !slices.Equal(keys, []string{"app", "apple", "banana"}) {
+		t.Fatal("Union changed its receiver:", keys)
+	}
+	if keys := b.Keys(); !slices.Equal(keys, []string{"appl", "banana", "cherry"}) {
+		t.Fatal("Union changed its argument:", keys)
+	}
+
+	union.Put("durian")
+	if a.Has("durian") || b.Has("durian") {
+		t.Fatal("Expected the union to not share nodes with its operands")
+	}
+
+	if keys := NewTrie().Union(NewTrie()).Keys(); len(keys) != 0 {
+		t.Fatal("Expected the union of empty tries to be empty; got", keys)
+	}
+}
+
+func TestTrieIntersection(t *testing.T) {
+	a := newTrieOf("apple", "banana", "app", "cherry")
+	b := newTrieOf("banana", "cherry", "appl", "apples")
+
+	for _, intersection := range []*Trie{a.Intersection(b), b.Intersection(a)} {
+		expected := []string{"banana", "cherry"}
+		if keys := intersection.Keys(); !slices.Equal(keys, expected) {
+			t.Fatal("Expected", expected, "got", keys)
+		}
+	}
+
+	if keys := a.Keys(); !slices.Equal(keys, []string{"app", "apple", "banana", "cherry"}) {
+		t.Fatal("Intersection changed its receiver:", keys)
+	}
+	if keys := b.Keys(); !slices.Equal(keys, []string{"appl", "apples", "banana", "cherry"}) {
+		t.Fatal("Intersection changed its argument:", keys)
+	}
+
+	empty := a.Intersection(newTrieOf("durian"))
+	if empty == nil || empty.Len() != 0 {
+		t.Fatal("Expected an empty, usable trie")
+	}
+	empty.Put("x")
+	if !empty.Has("x") {
+		t.Fatal("Expected an empty intersection to be usable")
+	}
+}
+
+func TestTrieDifference(t *testing.T) {
+	blocked := newTrieOf("spam", "spammer", "scam", "phish")
+	// Prefixes and extensions of blocked words, plus one exact match.
+	allowed := newTrieOf("spa", "scammers", "ph", "phish")
+
+	difference := blocked.Difference(allowed)
+	expected := []string{"scam", "spam", "spammer"}
+	if keys := difference.Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+
+	if blocked.Len() != 4 || allowed.Len() != 4 {
+		t.Fatal("Difference changed its operands")
+	}
+
+	if keys := blocked.Difference(blocked).Keys(); len(keys) != 0 {
+		t.Fatal("Expected a trie minus itself to be empty; got", keys)
+	}
+	if keys := blocked.Difference(NewTrie()).Keys(); !slices.Equal(keys, blocked.Keys()) {
+		t.Fatal("Expected a trie minus nothing to be itself; got", keys)
+	}
+}
+
+func TestTrieDiff(t *testing.T) {
+	old := newTrieOf("", "spam", "spammer", "scam", "phish", "été", "x")
+	current := newTrieOf("spa", "spam", "scammers", "phish", "étés", "y", "x")
+
+	added, removed := old.Diff(current)
+	expectedAdded := []string{"scammers", "spa", "y", "étés"}
+	expectedRemoved := []string{"", "scam", "spammer", "été"}
+	if !slices.Equal(added, expectedAdded) || !slices.Equal(removed, expectedRemoved) {
+		t.Fatalf("Expected %q added and %q removed; got %q and %q", expectedAdded, expectedRemoved, added, removed)
+	}
+	if old.Len() != 7 || current.Len() != 7 {
+		t.Fatal("Diff changed its operands")
+	}
+
+	// Compare against the set difference of the Keys, both ways round.
+	if keys := current.Difference(old).Keys(); !slices.Equal(keys, added) {
+		t.Fatal("Expected added to be", keys)
+	}
+	if keys := old.Difference(current).Keys(); !slices.Equal(keys, removed) {
+		t.Fatal("Expected removed to be", keys)
+	}
+
+	added, removed = old.Diff(old.Clone())
+	if added == nil || removed == nil || len(added)+len(removed) != 0 {
+		t.Fatal("Expected empty, non-nil diffs for the same strings; got", added, removed)
+	}
+	if added, _ := NewTrie().Diff(old); !slices.Equal(added, old.Keys()) {
+		t.Fatal("Expected everything to be added to an empty trie; got", added)
+	}
+}
+
+func TestTrieEqual(t *testing.T) {
+	a := newTrieOf("apple", "app", "banana")
+	b := newTrieOf("banana", "app", "apple")
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Fatal("Expected tries with the same strings to be equal")
+	}
+	if !NewTrie().Equal(NewTrie()) {
+		t.Fatal("Expected empty tries to be equal")
+	}
+
+	// Same structure, different ends.
+	if a.Equal(newTrieOf("apple", "banana")) || a.Equal(newTrieOf("apple", "appl", "banana")) {
+		t.Fatal("Expected tries with different strings to differ")
+	}
+
+	b.Put("cherry")
+	if a.Equal(b) {
+		t.Fatal("Expected tries to differ after a Put")
+	}
+	b.Delete("cherry")
+	if !a.Equal(b) {
+		t.Fatal("Expected tries to be equal again after a Delete")
+	}
+
+	// How children are kept doesn't matter.
+	ascii := NewTrieASCII()
+	for _, w := range a.Keys() {
+		ascii.Put(w)
+	}
+	if !a.Equal(ascii) || !ascii.Equal(a) {
+		t.Fatal("Expected an ASCII trie to equal a plain one with the same strings")
+	}
+
+	// A leftover empty branch is a structural difference, even though
+	// Keys can't see it.
+	c := newTrieOf("apple", "app", "banana")
+	c.addChild(&c.root, 'z')
+	if slices.Equal(a.Keys(), c.Keys()) == a.Equal(c) {
+		t.Fatal("Expected Equal to notice a dangling branch")
+	}
+}
@@ -0,0 +1,153 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import "slices"
+
+// Returns the length in runes of the longest string stored in the trie,
+// which is also the depth of the deepest node. An empty trie (or one
+// holding only the empty string) has height 0.
+//
+// Walks the trie with an explicit stack rather than recursing, so tries
+// full of very long strings can't blow the goroutine stack.
+func (t *Trie) Height() int {
+	type frame struct {
+		node  *trieNode
+		depth int
+	}
+
+	height := 0
+	stack := []frame{{&t.root, 0}}
+	for len(stack) != 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		height = max(height, f.depth)
+		for _, child := range f.node.children() {
+			stack = append(stack, frame{child, f.depth + 1})
+		}
+	}
+	return height
+}
+
+// Returns the number of nodes in the trie, not counting the root. Each
+// node is one rune, so this is the number of distinct non-empty prefixes
+// of the stored strings; prefixes shared by several strings are only
+// counted once.
+func (t *Trie) NodeCount() int {
+	return t.root.countNodes() - 1
+}
+
+// Returns every distinct rune used anywhere in the trie, in ascending
+// order. If they're all below utf8.RuneSelf, the trie would fit in one
+// made by NewTrieASCII.
+//
+// Never returns nil; an empty trie gives an empty slice.
+func (t *Trie) Alphabet() []rune {
+	seen := map[rune]bool{}
+	stack := []*trieNode{&t.root}
+	for len(stack) != 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for r, child := range node.children() {
+			seen[r] = true
+			stack = append(stack, child)
+		}
+	}
+
+	out := make([]rune, 0, len(seen))
+	for r := range seen {
+		out = append(out, r)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// A snapshot of a trie's shape, as returned by Trie.Stats.
+type TrieStats struct {
+	// The number of strings in the trie; the same as Len.
+	Words int
+	// The number of nodes, not counting the root; the same as NodeCount.
+	Nodes int
+	// The length in runes of the longest string; the same as Height.
+	Height int
+	// The mean and median length in runes of the strings in the trie.
+	// When there's an even number of strings, the median is the mean of
+	// the two middle lengths. Both are 0 for an empty trie.
+	AvgKeyLen, MedianKeyLen float64
+	// The mean number of children of a node, taken over internal nodes
+	// only (those with at least one child, including the root). Leaves
+	// are left out, since counting them would mostly measure how many
+	// strings there are. 0 if the trie has no nodes.
+	AvgBranching float64
+}
+
+// Returns a snapshot of the trie's shape. Everything in it is computed in
+// a single traversal, so this is cheaper than calling Len, Height and
+// NodeCount separately.
+func (t *Trie) Stats() TrieStats {
+	type frame struct {
+		node  *trieNode
+		depth int
+	}
+
+	var stats TrieStats
+	internal, totalLen := 0, 0
+	// lens[i] is how many strings are i runes long.
+	var lens []int
+	stack := []frame{{&t.root, 0}}
+	for len(stack) != 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		stats.Height = max(stats.Height, f.depth)
+		if f.node.isEnd {
+			for len(lens) <= f.depth {
+				lens = append(lens, 0)
+			}
+			lens[f.depth]++
+			stats.Words++
+			totalLen += f.depth
+		}
+		if n := f.node.numChildren(); n != 0 {
+			internal++
+			stats.Nodes += n
+		}
+		for _, child := range f.node.children() {
+			stack = append(stack, frame{child, f.depth + 1})
+		}
+	}
+
+	if stats.Words != 0 {
+		stats.AvgKeyLen = float64(totalLen) / float64(stats.Words)
+		stats.MedianKeyLen = float64(nthLen(lens, (stats.Words-1)/2)+nthLen(lens, stats.Words/2)) / 2
+	}
+	if internal != 0 {
+		stats.AvgBranching = float64(stats.Nodes) / float64(internal)
+	}
+	return stats
+}
+
+// Returns the nth smallest (from 0) length counted in lens, where lens[i]
+// is how many lengths equal i.
+func nthLen(lens []int, n int) int {
+	for length, count := range lens {
+		if n < count {
+			return length
+		}
+		n -= count
+	}
+	panic("nthLen: n out of range")
+}
@@ -0,0 +1,133 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrieHeight(t *testing.T) {
+	trie := NewTrie()
+	if h := trie.Height(); h != 0 {
+		t.Fatal("Expected an empty trie to have height 0; got", h)
+	}
+
+	trie.Put("")
+	if h := trie.Height(); h != 0 {
+		t.Fatal("Expected height 0 with just the empty string; got", h)
+	}
+
+	for _, n := range []string{"ab", "abcd", "x", "日本語"} {
+		trie.Put(n)
+	}
+	if h := trie.Height(); h != 4 {
+		t.Fatal("Expected height 4; got", h)
+	}
+
+	trie.Delete("abcd")
+	if h := trie.Height(); h != 3 {
+		t.Fatal("Expected height 3 after deleting the longest string; got", h)
+	}
+
+	long := strings.Repeat("a", 100000)
+	trie.Put(long)
+	if h := trie.Height(); h != len(long) {
+		t.Fatal("Expected height", len(long), "got", h)
+	}
+}
+
+func TestTrieNodeCount(t *testing.T) {
+	trie := NewTrie()
+	if n := trie.NodeCount(); n != 0 {
+		t.Fatal("Expected an empty trie to have no nodes; got", n)
+	}
+
+	trie.Put("")
+	if n := trie.NodeCount(); n != 0 {
+		t.Fatal("Expected the empty string to add no nodes; got", n)
+	}
+
+	// t, te, tea, ten, to, i, in, inn
+	for _, n := range []string{"tea", "ten", "to", "inn", "in"} {
+		trie.Put(n)
+	}
+	if n := trie.NodeCount(); n != 8 {
+		t.Fatal("Expected 8 nodes; got", n)
+	}
+
+	trie.Delete("in")
+	if n := trie.NodeCount(); n != 8 {
+		t.Fatal("Expected deleting a prefix to keep 8 nodes; got", n)
+	}
+	trie.Delete("inn")
+	if n := trie.NodeCount(); n != 5 {
+		t.Fatal("Expected 5 nodes after deleting inn; got", n)
+	}
+}
+
+func TestTrieStats(t *testing.T) {
+	trie := NewTrie()
+	if s := trie.Stats(); s != (TrieStats{}) {
+		t.Fatal("Expected zeroed stats for an empty trie; got", s)
+	}
+
+	// t, te, tea, ten, to, i, in, inn
+	for _, n := range []string{"tea", "ten", "to", "inn", "in"} {
+		trie.Put(n)
+	}
+	s := trie.Stats()
+	if s.Words != trie.Len() || s.Nodes != trie.NodeCount() || s.Height != trie.Height() {
+		t.Fatal("Expected Stats to agree with Len, NodeCount and Height; got", s)
+	}
+	// Lengths 3, 3, 2, 3, 2.
+	if s.AvgKeyLen != 13.0/5 || s.MedianKeyLen != 3 {
+		t.Fatal("Expected average key length 2.6 and median 3; got", s)
+	}
+	// Internal nodes: root (2), t (2), te (2), i (1), in (1).
+	if s.AvgBranching != 8.0/5 {
+		t.Fatal("Expected average branching 1.6; got", s.AvgBranching)
+	}
+
+	trie.Delete("tea")
+	if s := trie.Stats(); s.MedianKeyLen != 2.5 {
+		t.Fatal("Expected the median of an even count to be averaged; got", s.MedianKeyLen)
+	}
+
+	trie = NewTrie()
+	trie.Put("")
+	if s := trie.Stats(); s != (TrieStats{Words: 1}) {
+		t.Fatal("Expected just one empty word; got", s)
+	}
+}
+
+func TestTrieAlphabet(t *testing.T) {
+	if a := NewTrie().Alphabet(); a == nil || len(a) != 0 {
+		t.Fatal("Expected an empty, non-nil alphabet; got", a)
+	}
+
+	trie := newTrieOf("cab", "bad", "", "été")
+	if a := trie.Alphabet(); !slices.Equal(a, []rune("abcdté")) {
+		t.Fatalf("Expected %q; got %q", "abcdté", string(a))
+	}
+
+	trie.Delete("été")
+	if a := trie.Alphabet(); !slices.Equal(a, []rune("abcd")) {
+		t.Fatalf("Expected %q once été is gone; got %q", "abcd", string(a))
+	}
+}
@@ -17,8 +17,14 @@
 package gollections
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"math/rand"
+	"slices"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 // Indirectly tests PutRune too.
@@ -136,11 +142,1655 @@ func TestTrieDelete(t *testing.T) {
 	}
 }
 
+// Fails unless every node below node has its parent set to the node
+// above it.
+func checkParents(t *testing.T, node *trieNode) {
+	t.Helper()
+	for r, child := range node.children() {
+		if child.parent != node {
+			t.Fatalf("Expected the parent of %q to be the node above it", r)
+		}
+		checkParents(t, child)
+	}
+}
+
+// Checks that every node's cached count of the strings at or below it is
+// right, returning the count for node.
+func checkEnds(t *testing.T, node *trieNode) int {
+	t.Helper()
+	n := 0
+	if node.isEnd {
+		n++
+	}
+	for _, child := range node.children() {
+		n += checkEnds(t, child)
+	}
+	if node.ends != n {
+		t.Fatalf("Expected %d strings at or below %q; the cached count is %d", n, node.word(), node.ends)
+	}
+	return n
+}
+
+func TestTrieCachedEnds(t *testing.T) {
+	rand.Seed(0) // Arbitrary seed
+	alphabet := []rune("abé")
+	randString := func() string {
+		buf := make([]rune, rand.Intn(5))
+		for i := range buf {
+			buf[i] = alphabet[rand.Intn(len(alphabet))]
+		}
+		return string(buf)
+	}
+
+	for _, trie := range []*Trie{NewTrie(), NewTrieCapped(20), NewTrieArena()} {
+		for i := 0; i < 3000; i++ {
+			s := randString()
+			switch rand.Intn(6) {
+			case 0, 1:
+				trie.Put(s)
+			case 2:
+				trie.Delete(s)
+			case 3:
+				trie.PutN(s, rand.Intn(3)-1)
+			case 4:
+				if rand.Intn(10) == 0 {
+					trie.DeletePrefix(s)
+				}
+			case 5:
+				trie.PutBytes([]byte(s))
+			}
+			if n := checkEnds(t, &trie.root); n != trie.Len() {
+				t.Fatal("Expected the root's count to match Len after op", i)
+			}
+
+			q := randString()
+			if n := trie.CountWithPrefix(q); n != len(trie.KeysWithPrefix(q)) {
+				t.Fatalf("CountWithPrefix(%q) = %d after op %d; expected %d", q, n, i, len(trie.KeysWithPrefix(q)))
+			}
+		}
+
+		checkEnds(t, &trie.Clone().root)
+		if sub, ok := trie.SubTrie("a"); ok {
+			checkEnds(t, &sub.root)
+		}
+		data, _ := trie.MarshalBinary()
+		decoded := NewTrie()
+		decoded.UnmarshalBinary(data)
+		checkEnds(t, &decoded.root)
+		concurrent := NewTrieConcurrent(trie.Keys(), 2)
+		checkEnds(t, &concurrent.root)
+		trie.Clear()
+		checkEnds(t, &trie.root)
+	}
+}
+
+func TestTrieParents(t *testing.T) {
+	trie := newTrieOf("a", "ab", "abc", "b", "\u00e9t\u00e9", "")
+	checkParents(t, &trie.root)
+	if trie.root.parent != nil {
+		t.Fatal("Expected the root to have no parent")
+	}
+
+	clone := trie.Clone()
+	checkParents(t, &clone.root)
+	sub, _ := trie.SubTrie("a")
+	checkParents(t, &sub.root)
+	if sub.root.parent != nil {
+		t.Fatal("Expected the root of a sub-trie to have no parent")
+	}
+
+	data, _ := trie.GobEncode()
+	decoded := NewTrie()
+	if err := decoded.GobDecode(data); err != nil {
+		t.Fatal("Unexpected error decoding", err)
+	}
+	checkParents(t, &decoded.root)
+
+	// Deletes on copies have to prune their own nodes, not the
+	// original's.
+	clone.Delete("abc")
+	decoded.DeletePrefix("\u00e9")
+	if !trie.Has("abc") || !trie.Has("\u00e9t\u00e9") {
+		t.Fatal("Expected deletes on copies to leave the original alone")
+	}
+	if clone.HasPrefix("abc") || decoded.HasPrefix("\u00e9") {
+		t.Fatal("Expected deletes on copies to prune the copies")
+	}
+	checkParents(t, &clone.root)
+	checkParents(t, &decoded.root)
+}
+
+func TestTrieDeleteLastWords(t *testing.T) {
+	// Deleting the only word clears out the root entirely.
+	for _, only := range []string{"a", "abc", "\u00e9t\u00e9"} {
+		trie := newTrieOf(only)
+		trie.Delete(only)
+		if trie.Has(only) || trie.HasPrefix(only[:1]) || !trie.IsEmpty() || trie.NodeCount() != 0 {
+			t.Fatal("Expected deleting", only, "to leave an empty trie")
+		}
+		trie.Put("b")
+		if !trie.Has("b") || trie.Len() != 1 {
+			t.Fatal("Expected the trie to be usable after deleting", only)
+		}
+	}
+
+	// Deleting one of two single-rune words leaves the other alone.
+	trie := newTrieOf("a", "b")
+	trie.Delete("a")
+	if trie.Has("a") || !trie.Has("b") || trie.NodeCount() != 1 {
+		t.Fatal("Expected only b after deleting a; got", trie.Keys())
+	}
+	trie.Delete("b")
+	if !trie.IsEmpty() || trie.NodeCount() != 0 {
+		t.Fatal("Expected an empty trie after deleting b; got", trie.Keys())
+	}
+
+	// Same, with DeletePrefix.
+	trie = newTrieOf("ab", "b")
+	if n := trie.DeletePrefix("a"); n != 1 || trie.Has("ab") || !trie.Has("b") {
+		t.Fatal("Expected DeletePrefix(a) to remove only ab; got", n, trie.Keys())
+	}
+	if n := trie.DeletePrefix("b"); n != 1 || !trie.IsEmpty() || trie.NodeCount() != 0 {
+		t.Fatal("Expected DeletePrefix(b) to empty the trie; got", n, trie.Keys())
+	}
+}
+
+func TestTrieKeys(t *testing.T) {
+	trie := NewTrie()
+
+	keys := trie.Keys()
+	if keys == nil || len(keys) != 0 {
+		t.Fatal("Expected empty, non-nil keys for an empty trie; got", keys)
+	}
+
+	putIn := []string{"mlp", "abc", "de", "ab", "fghi", "acl", "été"}
+	for _, n := range putIn {
+		trie.Put(n)
+	}
+
+	expected := []string{"ab", "abc", "acl", "de", "fghi", "mlp", "été"}
+	keys = trie.Keys()
+	if !slices.Equal(keys, expected) {
+		t.Fatal("Expected keys", expected, "got", keys)
+	}
+
+	trie.Delete("abc")
+	expected = []string{"ab", "acl", "de", "fghi", "mlp", "été"}
+	keys = trie.Keys()
+	if !slices.Equal(keys, expected) {
+		t.Fatal("Expected keys", expected, "after delete; got", keys)
+	}
+}
+
+func TestTrieToMap(t *testing.T) {
+	if got := NewTrie().ToMap(); got == nil || len(got) != 0 {
+		t.Fatal("Expected an empty, non-nil map; got", got)
+	}
+
+	trie := newTrieOf("ab", "abc", "", "été")
+	expected := map[string]bool{"ab": true, "abc": true, "": true, "été": true}
+	if got := trie.ToMap(); !maps.Equal(got, expected) {
+		t.Fatal("Expected", expected, "got", got)
+	}
+}
+
+func TestTrieKeysWithPrefix(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"car", "cart", "carbon", "cat", "dog", "ca\u00e9"} {
+		trie.Put(n)
+	}
+
+	expected := []string{"car", "carbon", "cart"}
+	if keys := trie.KeysWithPrefix("car"); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+
+	expected = []string{"car", "carbon", "cart", "cat", "ca\u00e9"}
+	if keys := trie.KeysWithPrefix("ca"); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+
+	for _, n := range []string{"cab", "dogs", "x", "\xff"} {
+		keys := trie.KeysWithPrefix(n)
+		if keys == nil || len(keys) != 0 {
+			t.Fatal("Expected empty, non-nil keys for prefix", n, "got", keys)
+		}
+	}
+}
+
+func TestTrieGroupByPrefix(t *testing.T) {
+	trie := newTrieOf("apple", "apricot", "banana", "b", "\u00e9t\u00e9", "\u00e9", "")
+
+	groups := trie.GroupByPrefix(2)
+	expected := map[string][]string{
+		"":        {""},
+		"ap":      {"apple", "apricot"},
+		"b":       {"b"},
+		"ba":      {"banana"},
+		"\u00e9":  {"\u00e9"},
+		"\u00e9t": {"\u00e9t\u00e9"},
+	}
+	if !maps.EqualFunc(groups, expected, slices.Equal) {
+		t.Fatal("Expected", expected, "got", groups)
+	}
+
+	if groups := trie.GroupByPrefix(0); !maps.EqualFunc(groups, map[string][]string{"": trie.Keys()}, slices.Equal) {
+		t.Fatal("Expected n = 0 to put everything under \"\"; got", groups)
+	}
+	if groups := NewTrie().GroupByPrefix(2); groups == nil || len(groups) != 0 {
+		t.Fatal("Expected empty, non-nil groups for an empty trie; got", groups)
+	}
+}
+
+func TestTrieLen(t *testing.T) {
+	trie := NewTrie()
+	if trie.Len() != 0 {
+		t.Fatal("Expected an empty trie to have Len 0; got", trie.Len())
+	}
+
+	for _, n := range []string{"foo", "foobar", "bar", "foo"} {
+		trie.Put(n)
+	}
+	if trie.Len() != 3 {
+		t.Fatal("Expected Len 3 after putting a duplicate; got", trie.Len())
+	}
+
+	trie.Put("\xff")
+	if trie.Len() != 3 {
+		t.Fatal("Expected failed Put to leave Len at 3; got", trie.Len())
+	}
+
+	// Deleting a prefix or something that isn't there changes nothing.
+	trie.Delete("fo")
+	trie.Delete("baz")
+	if trie.Len() != 3 {
+		t.Fatal("Expected Len 3 after no-op deletes; got", trie.Len())
+	}
+
+	trie.Delete("foo")
+	trie.Delete("foo")
+	if trie.Len() != 2 {
+		t.Fatal("Expected Len 2 after deleting foo; got", trie.Len())
+	}
+
+	if trie.Len() != len(trie.Keys()) {
+		t.Fatal("Len disagrees with Keys:", trie.Len(), trie.Keys())
+	}
+}
+
+func TestTrieIsEmpty(t *testing.T) {
+	trie := NewTrie()
+	if !trie.IsEmpty() {
+		t.Fatal("Expected a new trie to be empty")
+	}
+
+	trie.Put("")
+	if trie.IsEmpty() {
+		t.Fatal("Expected a trie holding the empty string not to be empty")
+	}
+	trie.Delete("")
+	trie.Put("abc")
+	if trie.IsEmpty() {
+		t.Fatal("Expected a trie holding abc not to be empty")
+	}
+
+	trie.Delete("abc")
+	if !trie.IsEmpty() {
+		t.Fatal("Expected the trie to be empty after deleting everything")
+	}
+	trie.Put("x")
+	trie.Clear()
+	if !trie.IsEmpty() {
+		t.Fatal("Expected the trie to be empty after Clear")
+	}
+}
+
+func TestTrieEmptyString(t *testing.T) {
+	trie := NewTrie()
+	if trie.Has("") || trie.HasPrefix("") {
+		t.Fatal("Expected an empty trie to not have the empty string")
+	}
+
+	trie.Put("a")
+	if trie.Has("") {
+		t.Fatal("Expected empty string to not be found before putting it")
+	}
+	if !trie.HasPrefix("") {
+		t.Fatal("Expected empty string to be a prefix of a")
+	}
+
+	trie.Put("")
+	if !trie.Has("") || !trie.HasPrefix("") {
+		t.Fatal("Expected to find the empty string after putting it")
+	}
+	if trie.Len() != 2 {
+		t.Fatal("Expected Len 2; got", trie.Len())
+	}
+	if keys := trie.Keys(); !slices.Equal(keys, []string{"", "a"}) {
+		t.Fatal("Expected keys to include the empty string; got", keys)
+	}
+
+	// Deleting a with the empty string stored must leave the root alone.
+	trie.Delete("a")
+	if trie.Has("a") || !trie.Has("") {
+		t.Fatal("Expected only the empty string to remain")
+	}
+
+	trie.Put("a")
+	trie.Delete("")
+	if trie.Has("") || !trie.Has("a") || !trie.HasPrefix("") {
+		t.Fatal("Expected only a to remain after deleting the empty string")
+	}
+
+	trie.Delete("a")
+	if trie.HasPrefix("") || trie.Len() != 0 {
+		t.Fatal("Expected the trie to be empty")
+	}
+
+	// Deleting the empty string when it's the only key.
+	trie.Put("")
+	trie.Delete("")
+	if trie.Has("") || trie.HasPrefix("") || trie.Len() != 0 {
+		t.Fatal("Expected the trie to be empty")
+	}
+}
+
+func TestTrieClear(t *testing.T) {
+	trie := NewTrie()
+	putIn := []string{"", "abc", "de", "fghi", "acl"}
+	for _, n := range putIn {
+		trie.Put(n)
+	}
+
+	trie.Clear()
+	for _, n := range putIn {
+		if trie.Has(n) || trie.HasPrefix(n) {
+			t.Fatal("Expected not to find", n, "after Clear")
+		}
+	}
+	if trie.Len() != 0 || len(trie.Keys()) != 0 {
+		t.Fatal("Expected an empty trie after Clear")
+	}
+
+	// The trie is still usable afterward.
+	trie.Put("abc")
+	if !trie.Has("abc") || trie.Len() != 1 {
+		t.Fatal("Expected to find abc after reusing a cleared trie")
+	}
+}
+
+func TestTrieClone(t *testing.T) {
+	trie := NewTrie()
+	putIn := []string{"abc", "de", "fghi", "acl"}
+	for _, n := range putIn {
+		trie.Put(n)
+	}
+
+	clone := trie.Clone()
+	if !slices.Equal(trie.Keys(), clone.Keys()) || clone.Len() != trie.Len() {
+		t.Fatal("Expected clone to have the same keys; got", clone.Keys())
+	}
+
+	// Mutating the clone leaves the original alone...
+	clone.Put("abcd")
+	clone.Delete("de")
+	if trie.Has("abcd") || !trie.Has("de") || trie.Len() != len(putIn) {
+		t.Fatal("Mutating the clone changed the original:", trie.Keys())
+	}
+
+	// ...and vice versa.
+	trie.Put("xyz")
+	trie.Delete("fghi")
+	if clone.Has("xyz") || !clone.Has("fghi") {
+		t.Fatal("Mutating the original changed the clone:", clone.Keys())
+	}
+
+	expected := []string{"abc", "abcd", "acl", "fghi"}
+	if keys := clone.Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected clone keys", expected, "got", keys)
+	}
+}
+
+func TestTrieSplitAt(t *testing.T) {
+	words := []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "\u00e0", "\u00e0b"}
+	for _, trie := range []*Trie{newTrieOf(words...), NewTrieCapped(100), NewTrieASCII()} {
+		for _, w := range words {
+			trie.Put(w)
+		}
+		keys := trie.Keys()
+
+		for _, pivot := range append(slices.Clone(keys), "aa", "abb", "abz", "bb", "d", "\u00e0a", "\u00e1", "ab\xff") {
+			lo, hi := trie.SplitAt(pivot)
+			i, _ := slices.BinarySearch(keys, strings.ToValidUTF8(pivot, "\ufffd"))
+			if !slices.Equal(lo.Keys(), keys[:i]) || !slices.Equal(hi.Keys(), keys[i:]) {
+				t.Fatalf("SplitAt(%q): expected %q and %q; got %q and %q", pivot, keys[:i], keys[i:], lo.Keys(), hi.Keys())
+			}
+			if lo.Len()+hi.Len() != trie.Len() {
+				t.Fatalf("SplitAt(%q): expected Lens to add up to %d; got %d and %d", pivot, trie.Len(), lo.Len(), hi.Len())
+			}
+			for _, half := range []*Trie{lo, hi} {
+				checkParents(t, &half.root)
+				checkEnds(t, &half.root)
+				if half.ascii != trie.ascii || half.capacity != trie.capacity {
+					t.Fatal("Expected both halves to keep the trie's options")
+				}
+			}
+		}
+
+		// The halves don't share anything with the trie or each other.
+		lo, hi := trie.SplitAt("b")
+		lo.Put("az")
+		hi.Delete("bab")
+		if trie.Has("az") || !trie.Has("bab") || hi.Has("az") || trie.Len() != len(keys) {
+			t.Fatal("Expected changing the halves to leave the trie alone")
+		}
+	}
+}
+
+func TestTrieSubTrie(t *testing.T) {
+	trie := newTrieOf("com.example.a", "com.example.bc", "com.example.", "com.other", "org")
+
+	sub, ok := trie.SubTrie("com.example.")
+	if !ok {
+		t.Fatal("Expected to find com.example.")
+	}
+	if expected := []string{"", "a", "bc"}; !slices.Equal(sub.Keys(), expected) {
+		t.Fatal("Expected", expected, "got", sub.Keys())
+	}
+	if sub.Len() != 3 {
+		t.Fatal("Expected a sub-trie of size 3; got", sub.Len())
+	}
+
+	// Changes to either side don't show up in the other.
+	sub.Put("d")
+	sub.Delete("a")
+	if trie.Has("com.example.d") || !trie.Has("com.example.a") {
+		t.Fatal("Expected changes to the sub-trie to leave the parent alone")
+	}
+	trie.Delete("com.example.bc")
+	if !sub.Has("bc") {
+		t.Fatal("Expected changes to the parent to leave the sub-trie alone")
+	}
+
+	if sub, ok := trie.SubTrie("com.ex"); !ok || !slices.Equal(sub.Keys(), []string{"ample.", "ample.a"}) {
+		t.Fatal("Expected a mid-edge prefix to work; got", sub, ok)
+	}
+	if sub, ok := trie.SubTrie(""); !ok || !trie.Equal(sub) {
+		t.Fatal("Expected SubTrie(\"\") to copy the whole trie")
+	}
+	if sub, ok := trie.SubTrie("net"); ok || sub != nil {
+		t.Fatal("Expected no sub-trie for a missing prefix")
+	}
+
+	fold := NewTrieFold()
+	fold.Put("Hello")
+	if sub, ok := fold.SubTrie("HE"); !ok || !sub.Has("LLO") {
+		t.Fatal("Expected SubTrie to keep case folding")
+	}
+}
+
+func TestTrieWalk(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"b", "ab", "a", "abc", "ac", "c"} {
+		trie.Put(n)
+	}
+
+	var seen []string
+	trie.Walk(func(word string) bool {
+		seen = append(seen, word)
+		return true
+	})
+	expected := []string{"a", "ab", "abc", "ac", "b", "c"}
+	if !slices.Equal(seen, expected) {
+		t.Fatal("Expected to walk", expected, "got", seen)
+	}
+
+	// Stopping at abc must skip its sibling ac and everything after.
+	seen = nil
+	trie.Walk(func(word string) bool {
+		seen = append(seen, word)
+		return word != "abc"
+	})
+	expected = []string{"a", "ab", "abc"}
+	if !slices.Equal(seen, expected) {
+		t.Fatal("Expected early stop to walk", expected, "got", seen)
+	}
+
+	NewTrie().Walk(func(word string) bool {
+		t.Fatal("Didn't expect to visit anything in an empty trie; got", word)
+		return true
+	})
+}
+
+func TestTrieIterators(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"car", "cart", "cat", "dog"} {
+		trie.Put(n)
+	}
+
+	if words := slices.Collect(trie.All()); !slices.Equal(words, trie.Keys()) {
+		t.Fatal("Expected All to match Keys; got", words)
+	}
+
+	expected := []string{"car", "cart"}
+	if words := slices.Collect(trie.WithPrefix("car")); !slices.Equal(words, expected) {
+		t.Fatal("Expected", expected, "got", words)
+	}
+
+	if words := slices.Collect(trie.WithPrefix("x")); len(words) != 0 {
+		t.Fatal("Expected nothing with prefix x; got", words)
+	}
+
+	var seen []string
+	for word := range trie.All() {
+		seen = append(seen, word)
+		if word == "cart" {
+			break
+		}
+	}
+	expected = []string{"car", "cart"}
+	if !slices.Equal(seen, expected) {
+		t.Fatal("Expected break to stop iteration at", expected, "got", seen)
+	}
+}
+
+func TestTrieCountWithPrefix(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"car", "cart", "carbon", "cat", "dog"} {
+		trie.Put(n)
+	}
+
+	counts := []struct {
+		prefix string
+		n      int
+	}{
+		{"", 5},
+		{"c", 4},
+		{"car", 3},
+		{"cart", 1},
+		{"d", 1},
+		{"cab", 0},
+		{"dogs", 0},
+		{"\xff", 0},
+	}
+	for _, c := range counts {
+		if n := trie.CountWithPrefix(c.prefix); n != c.n {
+			t.Fatal("Expected", c.n, "strings with prefix", c.prefix, "got", n)
+		}
+	}
+}
+
+func TestTrieLongestPrefixOf(t *testing.T) {
+	trie := NewTrie()
+	if _, ok := trie.LongestPrefixOf("anything"); ok {
+		t.Fatal("Expected no prefix in an empty trie")
+	}
+
+	for _, n := range []string{"the", "there", "th\u00e9", "a"} {
+		trie.Put(n)
+	}
+
+	cases := []struct {
+		s, prefix string
+		ok        bool
+	}{
+		{"thereafter", "there", true},
+		{"there", "there", true},
+		{"thermos", "the", true},
+		{"th\u00e9\u00e2tre", "th\u00e9", true},
+		{"th", "", false},
+		{"apple", "a", true},
+		{"b", "", false},
+		{"", "", false},
+		// Decoding stops at the bad byte, so "the" is the best we get.
+		{"the\xffre", "the", true},
+		{"\xffthe", "", false},
+	}
+	for _, c := range cases {
+		prefix, ok := trie.LongestPrefixOf(c.s)
+		if prefix != c.prefix || ok != c.ok {
+			t.Fatalf("LongestPrefixOf(%q): expected (%q, %v), got (%q, %v)",
+				c.s, c.prefix, c.ok, prefix, ok)
+		}
+	}
+
+	trie.Put("")
+	if prefix, ok := trie.LongestPrefixOf("b"); prefix != "" || !ok {
+		t.Fatal("Expected the stored empty string to be a prefix of b")
+	}
+}
+
+func TestTrieEachPrefixOf(t *testing.T) {
+	trie := newTrieOf("/a", "/a/b", "/a/b/c/d", "/ab", "/x")
+
+	var prefixes []string
+	trie.EachPrefixOf("/a/b/c", func(prefix string) bool {
+		prefixes = append(prefixes, prefix)
+		return true
+	})
+	if expected := []string{"/a", "/a/b"}; !slices.Equal(prefixes, expected) {
+		t.Fatal("Expected", expected, "got", prefixes)
+	}
+
+	// Stopping early, and the empty string counting.
+	trie.Put("")
+	prefixes = nil
+	trie.EachPrefixOf("/a/b/c/d", func(prefix string) bool {
+		prefixes = append(prefixes, prefix)
+		return len(prefixes) < 3
+	})
+	if expected := []string{"", "/a", "/a/b"}; !slices.Equal(prefixes, expected) {
+		t.Fatal("Expected", expected, "got", prefixes)
+	}
+
+	prefixes = nil
+	trie.EachPrefixOf("/a\xff/b", func(prefix string) bool {
+		prefixes = append(prefixes, prefix)
+		return true
+	})
+	if expected := []string{"", "/a"}; !slices.Equal(prefixes, expected) {
+		t.Fatal("Expected to stop at the bad byte with", expected, "got", prefixes)
+	}
+}
+
+func TestTrieAllPrefixesOf(t *testing.T) {
+	trie := newTrieOf("10.", "10.0.", "10.0.0.1", "192.168.", "caf\u00e9")
+
+	cases := []struct {
+		s        string
+		prefixes []string
+	}{
+		{"10.0.0.1", []string{"10.", "10.0.", "10.0.0.1"}},
+		{"10.0.1.1", []string{"10.", "10.0."}},
+		{"10.1.0.0", []string{"10."}},
+		{"172.16.0.1", []string{}},
+		{"", []string{}},
+		{"caf\u00e9s", []string{"caf\u00e9"}},
+	}
+	for _, c := range cases {
+		prefixes := trie.AllPrefixesOf(c.s)
+		if prefixes == nil || !slices.Equal(prefixes, c.prefixes) {
+			t.Fatalf("AllPrefixesOf(%q): expected %q, got %q", c.s, c.prefixes, prefixes)
+		}
+	}
+}
+
+func TestTrieLongestCommonPrefix(t *testing.T) {
+	cases := []struct {
+		words  []string
+		prefix string
+	}{
+		{nil, ""},
+		{[]string{"api/v1/users", "api/v1/items", "api/v1/"}, "api/v1/"},
+		{[]string{"api/v1/users", "api/v1/items"}, "api/v1/"},
+		{[]string{"ab", "abc"}, "ab"},
+		{[]string{"caf\u00e9s", "caf\u00e9"}, "caf\u00e9"},
+		{[]string{"only"}, "only"},
+		{[]string{"a", "b"}, ""},
+		{[]string{"", "abc"}, ""},
+	}
+	for _, c := range cases {
+		if prefix := newTrieOf(c.words...).LongestCommonPrefix(); prefix != c.prefix {
+			t.Fatalf("LongestCommonPrefix of %q: expected %q, got %q", c.words, c.prefix, prefix)
+		}
+	}
+}
+
+func TestTrieHasAnyAndHasAll(t *testing.T) {
+	trie := newTrieOf("abc", "abd", "")
+
+	if !trie.HasAny([]string{"x", "ab\xff", "abd"}) {
+		t.Fatal("Expected HasAny to find abd")
+	}
+	if trie.HasAny([]string{"ab", "abcd", "abc\xff"}) {
+		t.Fatal("Expected HasAny to find nothing")
+	}
+	if trie.HasAny(nil) {
+		t.Fatal("Expected HasAny of nothing to be false")
+	}
+
+	if !trie.HasAll([]string{"abc", "", "abd"}) {
+		t.Fatal("Expected HasAll to find everything")
+	}
+	if trie.HasAll([]string{"abc", "ab"}) {
+		t.Fatal("Expected HasAll to miss ab")
+	}
+	if !trie.HasAll(nil) {
+		t.Fatal("Expected HasAll of nothing to be true")
+	}
+}
+
+func TestTrieCommonPrefixLen(t *testing.T) {
+	trie := newTrieOf("日本語", "abc", "abd")
+
+	cases := map[string]int{
+		"":        0,
+		"x":       0,
+		"ab":      2,
+		"abx":     2,
+		"abcdef":  3,
+		"日本人":     2,
+		"ab\xffc": 2,
+	}
+	for s, want := range cases {
+		if got := trie.CommonPrefixLen(s); got != want {
+			t.Fatalf("Expected CommonPrefixLen(%q) to be %d; got %d", s, want, got)
+		}
+	}
+}
+
+func TestTrieHasErr(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"abc", "\ufffd"} {
+		if err := trie.Put(n); err != nil {
+			t.Fatal("Unexpected error putting", n, err)
+		}
+	}
+
+	if ok, err := trie.HasErr("abc"); !ok || err != nil {
+		t.Fatal("Expected to find abc without error; got", ok, err)
+	}
+	if ok, err := trie.HasErr("ab"); ok || err != nil {
+		t.Fatal("Expected to not find ab without error; got", ok, err)
+	}
+
+	// A literal U+FFFD is valid utf8, not a decode error.
+	if ok, err := trie.HasErr("\ufffd"); !ok || err != nil {
+		t.Fatal("Expected to find U+FFFD without error; got", ok, err)
+	}
+
+	// Bad bytes are reported whether they come before or after the
+	// point where the search falls off the trie.
+	for _, n := range []string{"ab\xff", "\xffabc", "xyz\xff", "abcd\xe9"} {
+		ok, err := trie.HasErr(n)
+		if ok || !errors.Is(err, ErrInvalidUTF8) {
+			t.Fatalf("Expected ErrInvalidUTF8 for %q; got %v, %v", n, ok, err)
+		}
+		if trie.Has(n) {
+			t.Fatalf("Expected Has(%q) to be false", n)
+		}
+	}
+
+	if err := trie.Put("\xff"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected Put to return ErrInvalidUTF8; got", err)
+	}
+}
+
+func TestTrieDeletePrefix(t *testing.T) {
+	putIn := []string{"tmp", "tmp/a", "tmp/b/c", "tmx", "usr/bin", "t"}
+	newTrie := func() *Trie {
+		trie := NewTrie()
+		for _, n := range putIn {
+			trie.Put(n)
+		}
+		return trie
+	}
+
+	cases := []struct {
+		prefix  string
+		removed int
+		left    []string
+	}{
+		// Prefix is itself a stored word; a sibling branch survives.
+		{"tmp", 3, []string{"t", "tmx", "usr/bin"}},
+		{"tmp/", 2, []string{"t", "tmp", "tmx", "usr/bin"}},
+		// Shared with other branches higher up.
+		{"tm", 4, []string{"t", "usr/bin"}},
+		{"t", 5, []string{"usr/bin"}},
+		// Nothing above usr/bin is needed, so the whole chain goes.
+		{"u", 1, []string{"t", "tmp", "tmp/a", "tmp/b/c", "tmx"}},
+		{"tmpx", 0, putIn},
+		{"\xff", 0, putIn},
+		{"", len(putIn), []string{}},
+	}
+	for _, c := range cases {
+		trie := newTrie()
+		if removed := trie.DeletePrefix(c.prefix); removed != c.removed {
+			t.Fatal("Expected DeletePrefix", c.prefix, "to remove", c.removed, "got", removed)
+		}
+		left := slices.Sorted(slices.Values(c.left))
+		if keys := trie.Keys(); !slices.Equal(keys, left) {
+			t.Fatal("Expected", left, "after DeletePrefix", c.prefix, "got", keys)
+		}
+		if trie.Len() != len(left) {
+			t.Fatal("Expected Len", len(left), "after DeletePrefix", c.prefix, "got", trie.Len())
+		}
+		if c.removed != 0 && trie.HasPrefix(c.prefix) && c.prefix != "" {
+			t.Fatal("Expected no nodes left for prefix", c.prefix)
+		}
+	}
+}
+
+func TestTrieRetainPrefix(t *testing.T) {
+	words := []string{"", "a", "ab", "abc", "abd", "abde", "ac", "b", "ba", "\u00e0"}
+	for _, prefix := range []string{"ab", "abd", "a", "", "abc", "x", "abx", "a\xff"} {
+		var log []string
+		trie := newObservedTrie(NewTrieCapped(100), &log)
+		for _, w := range words {
+			trie.Put(w)
+		}
+		log = nil
+
+		expected := []string{}
+		var deleted []string
+		for _, w := range trie.Keys() {
+			if strings.HasPrefix(w, prefix) && utf8.ValidString(prefix) {
+				expected = append(expected, w)
+			} else {
+				deleted = append(deleted, "-"+w)
+			}
+		}
+
+		if n := trie.RetainPrefix(prefix); n != len(words)-len(expected) {
+			t.Fatalf("RetainPrefix(%q): expected %d removed; got %d", prefix, len(words)-len(expected), n)
+		}
+		if !slices.Equal(trie.Keys(), expected) || trie.Len() != len(expected) {
+			t.Fatalf("RetainPrefix(%q): expected %q; got %q", prefix, expected, trie.Keys())
+		}
+		if !slices.Equal(log, deleted) {
+			t.Fatalf("RetainPrefix(%q): expected changes %q; got %q", prefix, deleted, log)
+		}
+		checkParents(t, &trie.root)
+		checkEnds(t, &trie.root)
+
+		if trie.order.Len() != trie.Len() || len(trie.orderOf) != trie.Len() {
+			t.Fatal("Expected the LRU order to hold only what's left")
+		}
+	}
+}
+
+func TestTrieDeletePrefixKeys(t *testing.T) {
+	trie := newTrieOf("car", "cart", "carbon", "cat", "c\u00e9", "dog")
+	nodes := trie.NodeCount()
+
+	removed := trie.DeletePrefixKeys("car")
+	if expected := []string{"car", "carbon", "cart"}; !slices.Equal(removed, expected) {
+		t.Fatal("Expected", expected, "got", removed)
+	}
+	if expected := []string{"cat", "c\u00e9", "dog"}; !slices.Equal(trie.Keys(), expected) || trie.Len() != 3 {
+		t.Fatal("Expected", expected, "left; got", trie.Keys())
+	}
+	// Only the "r" node and everything below it should be gone.
+	if trie.NodeCount() != nodes-len("rtbon") {
+		t.Fatal("Expected the car branch to be pruned; got", trie.NodeCount(), "nodes")
+	}
+
+	if removed := trie.DeletePrefixKeys("x"); removed == nil || len(removed) != 0 || trie.Len() != 3 {
+		t.Fatal("Expected an absent prefix to remove nothing; got", removed, trie.Keys())
+	}
+
+	removed = trie.DeletePrefixKeys("")
+	if expected := []string{"cat", "c\u00e9", "dog"}; !slices.Equal(removed, expected) || !trie.IsEmpty() {
+		t.Fatal("Expected DeletePrefixKeys(\"\") to empty the trie; got", removed, trie.Keys())
+	}
+}
+
+func TestTrieFold(t *testing.T) {
+	trie := NewTrieFold()
+	for _, n := range []string{"Apple", "BANANA", "cherry", "ÉCLAIR"} {
+		trie.Put(n)
+	}
+	trie.Put("APPLE")
+	if trie.Len() != 4 {
+		t.Fatal("Expected APPLE to be a duplicate of Apple; Len is", trie.Len())
+	}
+
+	for _, n := range []string{"apple", "APPLE", "aPpLe", "banana", "Cherry", "éclair", "Éclair"} {
+		if !trie.Has(n) {
+			t.Fatal("Expected to find", n)
+		}
+	}
+	for _, n := range []string{"AP", "bAn", "CHER", "ÉC"} {
+		if !trie.HasPrefix(n) || trie.Has(n) {
+			t.Fatal("Expected to find only the prefix", n)
+		}
+	}
+
+	expected := []string{"apple", "banana", "cherry", "éclair"}
+	if keys := trie.Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected folded keys", expected, "got", keys)
+	}
+	if keys := trie.KeysWithPrefix("BAN"); !slices.Equal(keys, []string{"banana"}) {
+		t.Fatal("Expected KeysWithPrefix to fold its prefix; got", keys)
+	}
+
+	trie.Delete("CHERRY")
+	if trie.Has("cherry") {
+		t.Fatal("Expected Delete to fold its input")
+	}
+
+	if clone := trie.Clone(); !clone.Has("BANANA") {
+		t.Fatal("Expected a clone to stay case-insensitive")
+	}
+
+	if err := trie.Put("BAD\xff"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8 from a folding trie; got", err)
+	}
+
+	// Plain tries stay case-sensitive.
+	plain := NewTrie()
+	plain.Put("Apple")
+	if plain.Has("apple") {
+		t.Fatal("Expected a plain trie to be case-sensitive")
+	}
+}
+
+func TestTrieNormalized(t *testing.T) {
+	const composed = "Andr\u00e9"
+	const decomposed = "Andre\u0301"
+
+	trie := NewTrieNormalized()
+	trie.Put(decomposed)
+	if !trie.Has(composed) || !trie.Has(decomposed) {
+		t.Fatal("Expected both encodings of", composed, "to be found")
+	}
+	if !trie.HasPrefix("Andre\u0301") || !trie.HasPrefix("Andr\u00e9") {
+		t.Fatal("Expected both encodings to be found as prefixes")
+	}
+
+	trie.Put(composed)
+	if trie.Len() != 1 {
+		t.Fatal("Expected both encodings to be one key; Len is", trie.Len())
+	}
+	if keys := trie.Keys(); !slices.Equal(keys, []string{composed}) {
+		t.Fatal("Expected keys in NFC; got", keys)
+	}
+
+	trie.Delete(composed)
+	if trie.Has(decomposed) || trie.Len() != 0 {
+		t.Fatal("Expected Delete to normalize its input")
+	}
+
+	// Plain tries treat the two encodings as different strings.
+	plain := NewTrie()
+	plain.Put(decomposed)
+	if plain.Has(composed) {
+		t.Fatal("Expected a plain trie to not normalize")
+	}
+}
+
+// Tries keep a few children in a slice, and switch to a map when there
+// are more. Both need to behave the same.
+func TestTrieManyChildren(t *testing.T) {
+	trie := NewTrie()
+	var expected []string
+	for _, r := range rand.Perm(3 * maxSmallChildren) {
+		s := "x" + string(rune('a'+r))
+		trie.Put(s)
+		expected = append(expected, s)
+		if !trie.Has(s) {
+			t.Fatal("Expected to find", s)
+		}
+	}
+	slices.Sort(expected)
+
+	if keys := trie.Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+	if keys := trie.Clone().Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected clone to have", expected, "got", keys)
+	}
+
+	for i, s := range expected {
+		if i%2 == 0 {
+			trie.Delete(s)
+		}
+	}
+	for i, s := range expected {
+		if trie.Has(s) != (i%2 != 0) {
+			t.Fatal("Unexpected Has result for", s, "after deletes")
+		}
+	}
+
+	// Same thing, but staying small.
+	small := NewTrie()
+	for _, s := range []string{"d", "b", "c", "a"} {
+		small.Put(s)
+	}
+	small.Delete("b")
+	if keys := small.Keys(); !slices.Equal(keys, []string{"a", "c", "d"}) {
+		t.Fatal("Expected [a c d]; got", keys)
+	}
+}
+
+func TestTrieASCII(t *testing.T) {
+	trie := NewTrieASCII()
+	putIn := []string{"", "abc", "de", "fghi", "acl", "~!@", "\x00\x7f"}
+	for _, n := range putIn {
+		if err := trie.Put(n); err != nil {
+			t.Fatal("Unexpected error putting", n, err)
+		}
+	}
+	for _, n := range putIn {
+		if !trie.Has(n) || !trie.HasPrefix(n) {
+			t.Fatal("Expected to find", n)
+		}
+	}
+	expected := slices.Sorted(slices.Values(putIn))
+	if keys := trie.Keys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+
+	for _, n := range []string{"abé", "\u0080", "日本"} {
+		if err := trie.Put(n); !errors.Is(err, ErrNotASCII) {
+			t.Fatal("Expected ErrNotASCII putting", n, "got", err)
+		}
+		if err := trie.PutBytes([]byte(n)); !errors.Is(err, ErrNotASCII) {
+			t.Fatal("Expected ErrNotASCII from PutBytes putting", n, "got", err)
+		}
+		if trie.Has(n) || trie.HasPrefix(n) {
+			t.Fatal("Didn't expect to find", n)
+		}
+	}
+	if trie.Len() != len(putIn) {
+		t.Fatal("Expected failed puts to leave Len alone; got", trie.Len())
+	}
+
+	trie.Delete("abc")
+	trie.Delete("de")
+	if trie.Has("abc") || trie.HasPrefix("d") || !trie.Has("acl") {
+		t.Fatal("Unexpected contents after deletes:", trie.Keys())
+	}
+
+	clone := trie.Clone()
+	if err := clone.Put("é"); !errors.Is(err, ErrNotASCII) {
+		t.Fatal("Expected a clone to stay ASCII-only; got", err)
+	}
+
+	trie.Clear()
+	if err := trie.Put("é"); !errors.Is(err, ErrNotASCII) {
+		t.Fatal("Expected a cleared trie to stay ASCII-only; got", err)
+	}
+	trie.Put("abc")
+	if !trie.Has("abc") || trie.Len() != 1 {
+		t.Fatal("Expected to reuse a cleared ASCII trie")
+	}
+}
+
+func TestTriePutRollback(t *testing.T) {
+	trie := newTrieOf("abc", "abd", "x")
+	nodes := trie.NodeCount()
+
+	// Each of these gets partway in, maybe past a shared prefix, before
+	// going bad.
+	for _, n := range []string{"\xff", "ab\xff", "abcdef\xff", "xyz\xffxyz", "new\xc3"} {
+		if err := trie.Put(n); !errors.Is(err, ErrInvalidUTF8) {
+			t.Fatal("Expected ErrInvalidUTF8 putting", n, "got", err)
+		}
+		if trie.NodeCount() != nodes || trie.Len() != 3 {
+			t.Fatal("Expected a failed put of", n, "to leave the trie alone; got", trie.Keys(), trie.NodeCount())
+		}
+	}
+	if !slices.Equal(trie.Keys(), []string{"abc", "abd", "x"}) {
+		t.Fatal("Unexpected contents after failed puts:", trie.Keys())
+	}
+
+	ascii := NewTrieASCII()
+	ascii.Put("abc")
+	nodes = ascii.NodeCount()
+	cases := []struct {
+		s   string
+		err error
+	}{
+		{"abcd\u00e9", ErrNotASCII},
+		{"ab\u00e9\xff", ErrInvalidUTF8},
+		// Bad utf8 wins, even if it's after the first non-ASCII rune.
+		{"xy\u00e9z\xff", ErrInvalidUTF8},
+		{"xy\xffz", ErrInvalidUTF8},
+	}
+	for _, c := range cases {
+		if err := ascii.Put(c.s); !errors.Is(err, c.err) {
+			t.Fatalf("Put(%q): expected %v, got %v", c.s, c.err, err)
+		}
+		if ascii.NodeCount() != nodes || ascii.Len() != 1 {
+			t.Fatalf("Expected a failed put of %q to leave the trie alone; got %v", c.s, ascii.Keys())
+		}
+	}
+}
+
+func TestTriePutNew(t *testing.T) {
+	trie := NewTrie()
+
+	puts := []struct {
+		s     string
+		added bool
+	}{
+		{"foobar", true},
+		{"foobar", false},
+		// Already a prefix, but not a word yet.
+		{"foo", true},
+		{"foo", false},
+		{"", true},
+		{"", false},
+	}
+	for _, p := range puts {
+		added, err := trie.PutNew(p.s)
+		if err != nil || added != p.added {
+			t.Fatalf("PutNew(%q): expected %v, got %v, %v", p.s, p.added, added, err)
+		}
+	}
+
+	added, err := trie.PutNew("bad\xff")
+	if added || !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected PutNew to fail with ErrInvalidUTF8; got", added, err)
+	}
+
+	trie.Delete("foo")
+	if added, _ := trie.PutNew("foo"); !added {
+		t.Fatal("Expected foo to be new again after deleting it")
+	}
+
+	fold := NewTrieFold()
+	fold.PutNew("Foo")
+	if added, _ := fold.PutNew("FOO"); added {
+		t.Fatal("Expected FOO to not be new in a folding trie holding Foo")
+	}
+}
+
+func TestTrieMaxKeyRunes(t *testing.T) {
+	trie := NewTrieMaxKeyRunes(3)
+	trie.Put("ab")
+	for _, n := range []string{"", "abc", "été", "日本語", "\U0001F600\U0001F600\U0001F600"} {
+		if err := trie.Put(n); err != nil {
+			t.Fatalf("Unexpected error putting %q: %v", n, err)
+		}
+	}
+	for _, n := range []string{"abcd", "étés", "日本語s", "abxyz"} {
+		if err := trie.Put(n); !errors.Is(err, ErrKeyTooLong) {
+			t.Fatalf("Expected ErrKeyTooLong putting %q; got %v", n, err)
+		}
+		if err := trie.PutBytes([]byte(n)); !errors.Is(err, ErrKeyTooLong) {
+			t.Fatalf("Expected ErrKeyTooLong putting bytes %q; got %v", n, err)
+		}
+		if err := trie.PutN(n, -1); !errors.Is(err, ErrKeyTooLong) {
+			t.Fatalf("Expected ErrKeyTooLong from PutN(%q, -1); got %v", n, err)
+		}
+	}
+	// Nothing's left behind by the strings that were too long.
+	if trie.Len() != 6 || trie.HasPrefix("abx") || trie.HasPrefix("étés") || trie.NodeCount() != 12 {
+		t.Fatal("Expected failed puts to leave nothing behind; got", trie.Keys())
+	}
+	checkEnds(t, &trie.root)
+
+	// Bad utf8 is reported first, wherever it is.
+	if err := trie.Put("abcd\xff"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+
+	fold := NewTrieMaxKeyRunes(2)
+	fold.fold = true
+	if err := fold.Put("AB"); err != nil || !fold.Has("ab") {
+		t.Fatal("Expected AB to fit once folded; got", err)
+	}
+
+	// Everything that copies the trie keeps the limit.
+	if err := trie.Clone().Put("abcd"); !errors.Is(err, ErrKeyTooLong) {
+		t.Fatal("Expected a clone to keep the limit; got", err)
+	}
+	tx := trie.Begin()
+	tx.Put("xy")
+	tx.Put("wxyz")
+	if err := tx.Commit(); !errors.Is(err, ErrKeyTooLong) || trie.Has("xy") {
+		t.Fatal("Expected the transaction to fail as a whole; got", err)
+	}
+
+	data, _ := newTrieOf("abc", "abcd").MarshalBinary()
+	if err := trie.UnmarshalBinary(data); !errors.Is(err, ErrKeyTooLong) || trie.Len() != 6 {
+		t.Fatal("Expected decoding a string that's too long to fail; got", err)
+	}
+	if err := trie.UnmarshalJSON([]byte(`["a", "abcd", "b"]`)); !errors.Is(err, ErrKeyTooLong) || trie.Len() != 6 {
+		t.Fatal("Expected unmarshaling a string that's too long to fail; got", err)
+	}
+	data, _ = newTrieOf("abc", "xyz").MarshalBinary()
+	if err := trie.UnmarshalBinary(data); err != nil || trie.Len() != 2 {
+		t.Fatal("Expected decoding short strings to work; got", err)
+	}
+}
+
+func TestTriePutLenient(t *testing.T) {
+	trie := NewTrie()
+	if n, err := trie.PutLenient("été"); n != 3 || err != nil || !trie.Has("été") {
+		t.Fatal("Expected all 3 runes stored without error; got", n, err)
+	}
+
+	n, err := trie.PutLenient("abc\xffdef")
+	if n != 3 || !errors.Is(err, ErrInvalidUTF8) || !strings.Contains(err.Error(), "byte 3") {
+		t.Fatal("Expected 3 good runes and ErrInvalidUTF8 at byte 3; got", n, err)
+	}
+	if trie.Has("abc") || trie.HasPrefix("a") || trie.Len() != 1 || trie.NodeCount() != 3 {
+		t.Fatal("Expected a string that was cut off to store nothing; got", trie.Keys())
+	}
+
+	if n, err := trie.PutLenient("\xffabc"); n != 0 || !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected no good runes; got", n, err)
+	}
+	if trie.Has("") || trie.Len() != 1 {
+		t.Fatal("Expected a string that's bad from the start to store nothing")
+	}
+
+	ascii := NewTrieASCII()
+	n, err = ascii.PutLenient("caf\u00e9")
+	if n != 3 || !errors.Is(err, ErrNotASCII) || ascii.Has("caf") || !ascii.IsEmpty() {
+		t.Fatal("Expected 3 good runes, ErrNotASCII and nothing stored; got", n, err)
+	}
+
+	fold := NewTrieFold()
+	if n, err := fold.PutLenient("ABC\xfe"); n != 3 || err == nil || fold.Has("abc") {
+		t.Fatal("Expected 3 good runes and nothing stored; got", n, err)
+	}
+	if n, err := fold.PutLenient("ABC"); n != 3 || err != nil || !fold.Has("abc") {
+		t.Fatal("Expected abc stored in folded form; got", n, err)
+	}
+}
+
+func TestTriePutFunc(t *testing.T) {
+	trie := newTrieOf("apple", "ban")
+	var dups []string
+	for _, n := range []string{"apple", "banana", "ban", "banana", "ba"} {
+		if err := trie.PutFunc(n, func() { dups = append(dups, n) }); err != nil {
+			t.Fatal("Unexpected error putting", n, err)
+		}
+	}
+	if expected := []string{"apple", "ban", "banana"}; !slices.Equal(dups, expected) {
+		t.Fatal("Expected duplicates", expected, "got", dups)
+	}
+	if trie.Len() != 4 {
+		t.Fatal("Expected Len 4; got", trie.Len())
+	}
+
+	called := false
+	if err := trie.PutFunc("apple\xff", func() { called = true }); !errors.Is(err, ErrInvalidUTF8) || called {
+		t.Fatal("Expected ErrInvalidUTF8 without calling onDup; got", err, called)
+	}
+	if err := trie.PutFunc("apple", nil); err != nil {
+		t.Fatal("Expected a nil onDup to be fine; got", err)
+	}
+}
+
+func TestTrieDeleteOk(t *testing.T) {
+	trie := NewTrie()
+	trie.Put("foobar")
+	trie.Put("baz")
+
+	// Only a prefix; must not touch anything.
+	if trie.DeleteOk("foo") {
+		t.Fatal("Expected deleting a prefix to report false")
+	}
+	if !trie.Has("foobar") || !trie.HasPrefix("foo") || trie.Len() != 2 {
+		t.Fatal("Expected deleting a prefix to leave the trie intact")
+	}
+
+	for _, n := range []string{"", "qux", "foobarbaz", "\xff"} {
+		if trie.DeleteOk(n) {
+			t.Fatalf("Expected deleting missing %q to report false", n)
+		}
+	}
+
+	if !trie.DeleteOk("foobar") {
+		t.Fatal("Expected deleting foobar to report true")
+	}
+	if trie.DeleteOk("foobar") {
+		t.Fatal("Expected deleting foobar twice to report false")
+	}
+	if trie.HasPrefix("foo") || trie.Len() != 1 {
+		t.Fatal("Expected foobar to be gone")
+	}
+}
+
+func TestTrieDeleteAll(t *testing.T) {
+	words := []string{"", "a", "ab", "abc", "abd", "b", "ba", "été"}
+	toDelete := []string{"abc", "x", "ab", "ab", "\xff", "a\xffb", "", "ét"}
+
+	trie, expected := newTrieOf(words...), newTrieOf(words...)
+	for _, s := range toDelete {
+		expected.Delete(s)
+	}
+	if n := trie.DeleteAll(toDelete); n != 3 {
+		t.Fatal("Expected 3 strings removed; got", n)
+	}
+	if !trie.Equal(expected) {
+		t.Fatal("Expected", expected.Keys(), "got", trie.Keys())
+	}
+	checkParents(t, &trie.root)
+
+	if n := trie.DeleteAll(nil); n != 0 {
+		t.Fatal("Expected nothing removed; got", n)
+	}
+}
+
+// Keys, Walk and All promise sorted output, whichever way each node
+// keeps its children.
+func TestTrieSortedOrder(t *testing.T) {
+	rand.Seed(0) // Arbitrary seed
+
+	// Enough runes for some nodes to switch their children to a map,
+	// with multi-byte ones mixed in.
+	alphabet := []rune("zyxwvutsrqponmlkjihgfedcbaé日本\U0001F600")
+	trie := NewTrie()
+	buf := make([]rune, 4)
+	for i := 0; i < 2000; i++ {
+		n := rand.Intn(len(buf) + 1)
+		for x := 0; x < n; x++ {
+			buf[x] = alphabet[rand.Intn(len(alphabet))]
+		}
+		trie.Put(string(buf[:n]))
+	}
+
+	keys := trie.Keys()
+	if !slices.IsSorted(keys) {
+		t.Fatal("Expected Keys to be sorted")
+	}
+	if !slices.Equal(slices.Collect(trie.All()), keys) {
+		t.Fatal("Expected All to yield the same order as Keys")
+	}
+	for i := 1; i < len(keys); i++ {
+		if slices.Compare([]rune(keys[i-1]), []rune(keys[i])) >= 0 {
+			t.Fatalf("Expected %q < %q by rune", keys[i-1], keys[i])
+		}
+	}
+}
+
+func TestTrieMinMax(t *testing.T) {
+	trie := NewTrie()
+	if _, ok := trie.Min(); ok {
+		t.Fatal("Expected no Min in an empty trie")
+	}
+	if _, ok := trie.Max(); ok {
+		t.Fatal("Expected no Max in an empty trie")
+	}
+
+	for _, n := range []string{"ab", "abc", "b", "ba", "\u00e9", "a"} {
+		trie.Put(n)
+	}
+	if min, ok := trie.Min(); min != "a" || !ok {
+		t.Fatal("Expected Min a; got", min, ok)
+	}
+	if max, ok := trie.Max(); max != "\u00e9" || !ok {
+		t.Fatal("Expected Max \u00e9; got", max, ok)
+	}
+
+	trie.Delete("\u00e9")
+	if max, ok := trie.Max(); max != "ba" || !ok {
+		t.Fatal("Expected Max to keep going past b to ba; got", max, ok)
+	}
+
+	trie.Put("")
+	if min, ok := trie.Min(); min != "" || !ok {
+		t.Fatal("Expected the empty string to be the Min; got", min, ok)
+	}
+
+	single := newTrieOf("only")
+	min, _ := single.Min()
+	max, _ := single.Max()
+	if min != "only" || max != "only" {
+		t.Fatal("Expected Min and Max of a single string to be it; got", min, max)
+	}
+}
+
+func TestTrieFloorCeiling(t *testing.T) {
+	trie := newTrieOf("b", "bat", "bath", "cab", "\u00e9t\u00e9")
+
+	cases := []struct {
+		s              string
+		floor, ceiling string
+		hasFloor       bool
+		hasCeiling     bool
+	}{
+		{"bat", "bat", "bat", true, true},
+		{"ba", "b", "bat", true, true},
+		{"batch", "bat", "bath", true, true},
+		{"bats", "bath", "cab", true, true},
+		{"a", "", "b", false, true},
+		{"", "", "b", false, true},
+		{"c", "bath", "cab", true, true},
+		{"cabbage", "cab", "\u00e9t\u00e9", true, true},
+		{"z", "cab", "\u00e9t\u00e9", true, true},
+		{"\u00e9", "cab", "\u00e9t\u00e9", true, true},
+		{"\u00e9u", "\u00e9t\u00e9", "", true, false},
+		{"\u00ff", "\u00e9t\u00e9", "", true, false},
+	}
+	for _, c := range cases {
+		floor, ok := trie.Floor(c.s)
+		if floor != c.floor || ok != c.hasFloor {
+			t.Fatalf("Floor(%q): expected (%q, %v), got (%q, %v)", c.s, c.floor, c.hasFloor, floor, ok)
+		}
+		ceiling, ok := trie.Ceiling(c.s)
+		if ceiling != c.ceiling || ok != c.hasCeiling {
+			t.Fatalf("Ceiling(%q): expected (%q, %v), got (%q, %v)", c.s, c.ceiling, c.hasCeiling, ceiling, ok)
+		}
+	}
+
+	if _, ok := trie.Floor("c\xff"); ok {
+		t.Fatal("Expected no Floor for invalid utf8")
+	}
+	if _, ok := trie.Ceiling("c\xff"); ok {
+		t.Fatal("Expected no Ceiling for invalid utf8")
+	}
+
+	trie.Put("")
+	if floor, ok := trie.Floor("a"); floor != "" || !ok {
+		t.Fatal("Expected the empty string as the Floor of a; got", floor, ok)
+	}
+	if _, ok := NewTrie().Ceiling(""); ok {
+		t.Fatal("Expected no Ceiling in an empty trie")
+	}
+}
+
+func TestTrieFloorCeilingAgainstKeys(t *testing.T) {
+	// Compare against a plain search of Keys, which are sorted the
+	// same way, on queries around every key.
+	trie := newTrieOf("a", "ab", "abc", "abd", "b", "ba", "bab", "c", "\u00e0", "\u00e0b")
+	keys := trie.Keys()
+	for _, k := range append(slices.Clone(keys), "", "aa", "abb", "abe", "bb", "bac", "d", "\u00e0a", "\u00e0c", "\u00e1") {
+		for _, q := range []string{k, k + "a", k + "z"} {
+			i, found := slices.BinarySearch(keys, q)
+			floor, ok := trie.Floor(q)
+			switch {
+			case found && (floor != q || !ok):
+				t.Fatalf("Floor(%q): expected itself, got (%q, %v)", q, floor, ok)
+			case !found && i == 0 && ok:
+				t.Fatalf("Floor(%q): expected none, got %q", q, floor)
+			case !found && i > 0 && (floor != keys[i-1] || !ok):
+				t.Fatalf("Floor(%q): expected %q, got (%q, %v)", q, keys[i-1], floor, ok)
+			}
+
+			ceiling, ok := trie.Ceiling(q)
+			switch {
+			case i == len(keys) && ok:
+				t.Fatalf("Ceiling(%q): expected none, got %q", q, ceiling)
+			case i < len(keys) && (ceiling != keys[i] || !ok):
+				t.Fatalf("Ceiling(%q): expected %q, got (%q, %v)", q, keys[i], ceiling, ok)
+			}
+		}
+	}
+}
+
+func TestTriePredecessorSuccessor(t *testing.T) {
+	trie := newTrieOf("a", "ab", "abc", "abd", "b", "ba", "bab", "c", "\u00e0", "\u00e0b")
+	keys := trie.Keys()
+
+	// Stepping from one end to the other visits every key.
+	for i, k := range keys {
+		pred, ok := trie.Predecessor(k)
+		if i == 0 && ok {
+			t.Fatalf("Expected no predecessor of %q; got %q", k, pred)
+		}
+		if i > 0 && (!ok || pred != keys[i-1]) {
+			t.Fatalf("Predecessor(%q): expected %q, got (%q, %v)", k, keys[i-1], pred, ok)
+		}
+
+		succ, ok := trie.Successor(k)
+		if i == len(keys)-1 && ok {
+			t.Fatalf("Expected no successor of %q; got %q", k, succ)
+		}
+		if i < len(keys)-1 && (!ok || succ != keys[i+1]) {
+			t.Fatalf("Successor(%q): expected %q, got (%q, %v)", k, keys[i+1], succ, ok)
+		}
+	}
+
+	// Strings that aren't in the trie behave like Floor and Ceiling.
+	for _, q := range []string{"", "aa", "abb", "abz", "bb", "d", "\u00e0a", "\u00e1"} {
+		pred, ok := trie.Predecessor(q)
+		floor, fok := trie.Floor(q)
+		if pred != floor || ok != fok {
+			t.Fatalf("Predecessor(%q): expected (%q, %v), got (%q, %v)", q, floor, fok, pred, ok)
+		}
+		succ, ok := trie.Successor(q)
+		ceiling, cok := trie.Ceiling(q)
+		if succ != ceiling || ok != cok {
+			t.Fatalf("Successor(%q): expected (%q, %v), got (%q, %v)", q, ceiling, cok, succ, ok)
+		}
+	}
+
+	if _, ok := trie.Successor("ab\xff"); ok {
+		t.Fatal("Expected no successor of invalid utf8")
+	}
+}
+
+func TestTrieRangeKeys(t *testing.T) {
+	trie := newTrieOf("", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "\u00e0", "\u00e0b")
+	expect := func(lo, hi string, want ...string) {
+		t.Helper()
+		if got := trie.RangeKeys(lo, hi); !slices.Equal(got, want) || got == nil {
+			t.Fatalf("RangeKeys(%q, %q): expected %q, got %q", lo, hi, want, got)
+		}
+	}
+
+	expect("a", "b", "a", "ab", "abc", "abd")
+	expect("ab", "abd", "ab", "abc")
+	expect("aa", "abz", "ab", "abc", "abd")
+	expect("", "a", "")
+	expect("bb", "\u00e0", "c")
+	expect("c", "\uffff", "c", "\u00e0", "\u00e0b")
+	expect("b", "b")
+	expect("c", "a")
+	expect("a\xff", "b")
+
+	// Compare against filtering Keys, for ranges around every key.
+	keys := trie.Keys()
+	bounds := append(slices.Clone(keys), "aa", "abb", "bb", "d", "\u00e1")
+	for _, lo := range bounds {
+		for _, hi := range bounds {
+			want := []string{}
+			for _, k := range keys {
+				if lo <= k && k < hi {
+					want = append(want, k)
+				}
+			}
+			expect(lo, hi, want...)
+		}
+	}
+}
+
+func TestTrieRankSelect(t *testing.T) {
+	// In order: "", "a", "an", "and", "ant", "b", "be", "\u00e9"
+	trie := newTrieOf("and", "a", "be", "\u00e9", "ant", "", "an", "b")
+
+	ranks := map[string]int{
+		"":              0,
+		"a":             1,
+		"aa":            2,
+		"an":            2,
+		"and":           3,
+		"andy":          4,
+		"ant":           4,
+		"anz":           5,
+		"b":             5,
+		"ba":            6,
+		"be":            6,
+		"c":             7,
+		"\u00e9":        7,
+		"\u00e9t\u00e9": 8,
+		"z\xff":         7,
+	}
+	for s, rank := range ranks {
+		if r := trie.Rank(s); r != rank {
+			t.Fatalf("Rank(%q): expected %d, got %d", s, rank, r)
+		}
+	}
+
+	for i, k := range trie.Keys() {
+		if s, ok := trie.Select(i); s != k || !ok {
+			t.Fatalf("Select(%d): expected %q, got (%q, %v)", i, k, s, ok)
+		}
+		if r := trie.Rank(k); r != i {
+			t.Fatalf("Rank(%q): expected %d, got %d", k, i, r)
+		}
+	}
+	for _, i := range []int{-1, trie.Len(), trie.Len() + 10} {
+		if s, ok := trie.Select(i); ok {
+			t.Fatalf("Select(%d): expected nothing, got %q", i, s)
+		}
+	}
+
+	if r := NewTrie().Rank("abc"); r != 0 {
+		t.Fatal("Expected Rank 0 in an empty trie; got", r)
+	}
+}
+
+func TestTrieReverseKeys(t *testing.T) {
+	if keys := NewTrie().ReverseKeys(); keys == nil || len(keys) != 0 {
+		t.Fatal("Expected empty, non-nil keys for an empty trie; got", keys)
+	}
+
+	trie := NewTrie()
+	for _, n := range []string{"", "a", "ab", "abc", "b", "é", "z", "日本", "日"} {
+		trie.Put(n)
+	}
+	expected := []string{"日本", "日", "é", "z", "b", "abc", "ab", "a", ""}
+	if keys := trie.ReverseKeys(); !slices.Equal(keys, expected) {
+		t.Fatal("Expected", expected, "got", keys)
+	}
+
+	// Same thing with enough children for some nodes to use a map.
+	for i := 0; i < 3*maxSmallChildren; i++ {
+		trie.Put("m" + string(rune('a'+i)))
+	}
+	keys := trie.Keys()
+	slices.Reverse(keys)
+	if reverse := trie.ReverseKeys(); !slices.Equal(reverse, keys) {
+		t.Fatal("Expected ReverseKeys to be Keys reversed; got", reverse)
+	}
+}
+
+func TestTrieCompleteN(t *testing.T) {
+	trie := NewTrie()
+	for _, n := range []string{"car", "cart", "carbon", "cat", "dog"} {
+		trie.Put(n)
+	}
+
+	cases := []struct {
+		prefix  string
+		n       int
+		matches []string
+	}{
+		{"car", 2, []string{"car", "carbon"}},
+		{"car", 3, []string{"car", "carbon", "cart"}},
+		{"car", 10, []string{"car", "carbon", "cart"}},
+		{"", 1, []string{"car"}},
+		{"ca", 0, []string{}},
+		{"ca", -1, []string{}},
+		{"x", 5, []string{}},
+	}
+	for _, c := range cases {
+		matches := trie.CompleteN(c.prefix, c.n)
+		if matches == nil || !slices.Equal(matches, c.matches) {
+			t.Fatalf("CompleteN(%q, %d): expected %v, got %v", c.prefix, c.n, c.matches, matches)
+		}
+	}
+}
+
 // --------- Here be benchmarks ------------
 
 func BenchmarkTrieHas(b *testing.B) {
-	root := NewTrie()
+	benchmarkTrieHas(b, NewTrie())
+}
+
+func BenchmarkTrieHasASCII(b *testing.B) {
+	benchmarkTrieHas(b, NewTrieASCII())
+}
 
+func benchmarkTrieHas(b *testing.B, root *Trie) {
 	strings := []struct {
 		s  string
 		ok bool
@@ -172,6 +1822,19 @@ func BenchmarkTrieHas(b *testing.B) {
 }
 
 func BenchmarkLargeTrieSearch(b *testing.B) {
+	benchmarkLargeTrieSearch(b, NewTrie(), false)
+}
+
+func BenchmarkLargeTrieSearchASCII(b *testing.B) {
+	benchmarkLargeTrieSearch(b, NewTrieASCII(), false)
+}
+
+func BenchmarkLargeFrozenTrieSearch(b *testing.B) {
+	benchmarkLargeTrieSearch(b, NewTrie(), true)
+}
+
+// If freeze is set, the lookups go to trie.Freeze() instead of trie.
+func benchmarkLargeTrieSearch(b *testing.B, trie *Trie, freeze bool) {
 	const NUM_STRINGS = 1000000
 	const STR_LEN = 10
 	// Number of possible chars our strings can have
@@ -181,7 +1844,6 @@ func BenchmarkLargeTrieSearch(b *testing.B) {
 
 	rand.Seed(0) // Arbitrary seed
 
-	trie := NewTrie()
 	strings := make([]string, NUM_STRINGS)
 	buf := make([]rune, STR_LEN)
 
@@ -213,12 +1875,101 @@ func BenchmarkLargeTrieSearch(b *testing.B) {
 		}
 	}
 
+	has := trie.Has
+	if freeze {
+		has = trie.Freeze().Has
+	}
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		s := strings[i%len(strings)]
 		expected := i%2 != 0
-		if ok := trie.Has(s); ok != expected {
+		if ok := has(s); ok != expected {
 			b.Fatalf("Unexpected result for string %d (%s)", i, s)
 		}
 	}
 }
+
+// CompleteN should take about as long no matter how many completions
+// there are.
+func BenchmarkTrieCompleteN(b *testing.B) {
+	trie := NewTrie()
+	for i := 0; i < 100000; i++ {
+		trie.Put(fmt.Sprintf("a%06d", i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if len(trie.CompleteN("a", 10)) != 10 {
+			b.Fatal("Expected 10 completions")
+		}
+	}
+}
+
+// Should take about as long no matter how many strings have the prefix.
+func BenchmarkTrieCountWithPrefix(b *testing.B) {
+	trie := NewTrie()
+	for i := 0; i < 100000; i++ {
+		trie.Put(fmt.Sprintf("a%06d", i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if trie.CountWithPrefix("a0") != 100000 {
+			b.Fatal("Expected 100000 strings")
+		}
+	}
+}
+
+func BenchmarkTriePutLongKeys(b *testing.B) {
+	const NUM_KEYS = 100
+	const KEY_LEN = 1000
+
+	rand.Seed(0) // Arbitrary seed
+
+	keys := make([]string, NUM_KEYS)
+	buf := make([]rune, KEY_LEN)
+	for i := range keys {
+		for j := range buf {
+			buf[j] = rune('a' + rand.Intn(26))
+		}
+		keys[i] = string(buf)
+	}
+
+	trie := NewTrie()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := trie.Put(keys[i%len(keys)]); err != nil {
+			b.Fatal("Unexpected error", err)
+		}
+	}
+}
+
+// Mostly there to keep an eye on how much memory ASCII nodes take up.
+func BenchmarkTrieBuildASCII(b *testing.B) {
+	const NUM_STRINGS = 10000
+	const STR_LEN = 10
+
+	rand.Seed(0) // Arbitrary seed
+
+	strings := make([]string, NUM_STRINGS)
+	buf := make([]rune, STR_LEN)
+	for i := range strings {
+		for j := range buf {
+			buf[j] = rune('a' + rand.Intn(26))
+		}
+		strings[i] = string(buf)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie := NewTrieASCII()
+		for _, s := range strings {
+			trie.Put(s)
+		}
+	}
+}
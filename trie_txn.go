@@ -0,0 +1,106 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// A batch of Puts and Deletes that are applied to a trie all at once, or
+// not at all. Made by Trie.Begin.
+//
+// Nothing happens to the trie until Commit. A Txn isn't safe to use from
+// multiple goroutines, and the trie shouldn't be changed by anything else
+// while Commit runs.
+type Txn struct {
+	trie *Trie
+	ops  []txnOp
+}
+
+type txnOp struct {
+	s   string
+	put bool
+}
+
+// Starts a transaction on the trie.
+//
+// Never returns nil.
+func (t *Trie) Begin() *Txn {
+	return &Txn{trie: t}
+}
+
+// Adds a Put of s to the transaction.
+func (tx *Txn) Put(s string) {
+	tx.ops = append(tx.ops, txnOp{s, true})
+}
+
+// Adds a Delete of s to the transaction.
+func (tx *Txn) Delete(s string) {
+	tx.ops = append(tx.ops, txnOp{s, false})
+}
+
+// Applies every Put and Delete in the transaction, in the order they were
+// made, then empties it so it can be used again.
+//
+// If any Put would fail, nothing is applied, so the trie is exactly as
+// it was. The first such error is returned, wrapped with the index of
+// the operation (counting from 0); it can be checked with errors.Is. The
+// transaction is left as is.
+func (tx *Txn) Commit() error {
+	// Delete can't fail, and whether Put can doesn't depend on what's in
+	// the trie, so checking every Put up front is enough to know the
+	// whole batch will go through.
+	for i, op := range tx.ops {
+		if !op.put {
+			continue
+		}
+		if err := tx.trie.checkPut(op.s); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	for _, op := range tx.ops {
+		if op.put {
+			tx.trie.Put(op.s)
+		} else {
+			tx.trie.Delete(op.s)
+		}
+	}
+	tx.ops = nil
+	return nil
+}
+
+// Throws away every Put and Delete in the transaction, leaving it empty.
+func (tx *Txn) Rollback() {
+	tx.ops = nil
+}
+
+// Returns the error Put would give for s, without putting it.
+func (t *Trie) checkPut(s string) error {
+	if !utf8.ValidString(s) {
+		return ErrInvalidUTF8
+	}
+	s = t.canonical(s)
+	if err := t.checkASCII(s); err != nil {
+		return err
+	}
+	if t.maxKeyRunes > 0 && utf8.RuneCountInString(s) > t.maxKeyRunes {
+		return ErrKeyTooLong
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+  Copyright 2013 George Burgess IV
+
+  Licensed under the Apache License, Version 2.0 (the "License");
+  you may not use this file except in compliance with the License.
+  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+  Unless required by applicable law or agreed to in writing, software
+  distributed under the License is distributed on an "AS IS" BASIS,
+  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+  See the License for the specific language governing permissions and
+  limitations under the License.
+*/
+
+package gollections
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTxnCommit(t *testing.T) {
+	trie := newTrieOf("apple", "banana")
+	txn := trie.Begin()
+	txn.Put("cherry")
+	txn.Delete("apple")
+	txn.Put("apple pie")
+	// Later operations see earlier ones.
+	txn.Put("date")
+	txn.Delete("date")
+
+	if !slices.Equal(trie.Keys(), []string{"apple", "banana"}) {
+		t.Fatal("Expected nothing to change before Commit; got", trie.Keys())
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal("Unexpected error committing", err)
+	}
+	if expected := []string{"apple pie", "banana", "cherry"}; !slices.Equal(trie.Keys(), expected) {
+		t.Fatal("Expected", expected, "got", trie.Keys())
+	}
+
+	// The transaction is empty again after a Commit.
+	txn.Put("elder")
+	txn.Commit()
+	if trie.Len() != 4 || !trie.Has("elder") {
+		t.Fatal("Expected to reuse a committed transaction; got", trie.Keys())
+	}
+}
+
+func TestTxnFailedCommit(t *testing.T) {
+	trie := newTrieOf("apple", "banana")
+	before := trie.Clone()
+
+	txn := trie.Begin()
+	txn.Delete("apple")
+	txn.Put("cherry")
+	txn.Put("bad\xff")
+	txn.Put("date")
+	err := txn.Commit()
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal("Expected ErrInvalidUTF8; got", err)
+	}
+	if err.Error() != "operation 2: "+ErrInvalidUTF8.Error() {
+		t.Fatal("Expected the error to name the operation; got", err)
+	}
+	if !trie.Equal(before) || trie.Len() != before.Len() {
+		t.Fatal("Expected a failed Commit to leave the trie alone; got", trie.Keys())
+	}
+
+	ascii := NewTrieASCII()
+	txn = ascii.Begin()
+	txn.Put("abc")
+	txn.Put("é")
+	if err := txn.Commit(); !errors.Is(err, ErrNotASCII) || !ascii.IsEmpty() {
+		t.Fatal("Expected ErrNotASCII and no changes; got", err, ascii.Keys())
+	}
+}
+
+func TestTxnRollback(t *testing.T) {
+	trie := newTrieOf("apple")
+	txn := trie.Begin()
+	txn.Put("banana")
+	txn.Delete("apple")
+	txn.Rollback()
+	if err := txn.Commit(); err != nil {
+		t.Fatal("Unexpected error committing an empty transaction", err)
+	}
+	if !slices.Equal(trie.Keys(), []string{"apple"}) {
+		t.Fatal("Expected Rollback to throw everything away; got", trie.Keys())
+	}
+}